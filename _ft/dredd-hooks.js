@@ -31,3 +31,13 @@ hooks.beforeAll(function(t, done) {
    req.end();
    done();
 });
+
+// jobs only exist once submitted, and are not kept across the runs of the service,
+// while suggestions streams never end
+hooks.beforeEach(function(t, done) {
+   if (t.request.uri.indexOf('/drafts/content/suggestions/jobs/') === 0 ||
+       /\/suggestions\/stream(\?|$)/.test(t.request.uri)) {
+      t.skip = true;
+   }
+   done();
+});
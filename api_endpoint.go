@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	api "github.com/Financial-Times/api-endpoint"
+	"gopkg.in/yaml.v3"
+)
+
+// consumingPath is the endpoint whose consumed content types are the ones a validator is configured for.
+const consumingPath = "/drafts/content/suggestions"
+
+// apiEndpoint serves the OpenAPI specification of the service, with the content types consumed by the POST endpoint
+// generated from the validators currently configured, rather than hard-coded in the file.
+type apiEndpoint struct {
+	yml          []byte
+	contentTypes func() []string
+
+	mu           sync.Mutex
+	generatedFor string
+	endpoint     api.Endpoint
+}
+
+func newAPIEndpoint(apiYml string, contentTypes func() []string) (*apiEndpoint, error) {
+	yml, err := os.ReadFile(apiYml)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &apiEndpoint{yml: yml, contentTypes: contentTypes}
+	if _, err := e.current(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *apiEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := e.current()
+	if err != nil {
+		// the file was already checked, so only the generation from the validators can fail here
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	endpoint.ServeHTTP(w, r)
+}
+
+// current returns the endpoint for the content types currently configured, generating it again when they changed.
+func (e *apiEndpoint) current() (api.Endpoint, error) {
+	contentTypes := e.contentTypes()
+	key := strings.Join(contentTypes, "\n")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.endpoint != nil && e.generatedFor == key {
+		return e.endpoint, nil
+	}
+
+	yml, err := withConsumedContentTypes(e.yml, contentTypes)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := api.NewAPIEndpointForYAML(yml)
+	if err != nil {
+		return nil, err
+	}
+
+	e.endpoint, e.generatedFor = endpoint, key
+	return endpoint, nil
+}
+
+// withConsumedContentTypes replaces the consumes list of the POST endpoint of the specification.
+func withConsumedContentTypes(yml []byte, contentTypes []string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(yml, &doc); err != nil {
+		return nil, err
+	}
+
+	post := mappingValue(mappingValue(mappingValue(&doc, "paths"), consumingPath), "post")
+	if post == nil {
+		return nil, fmt.Errorf("no POST %s in the API specification", consumingPath)
+	}
+
+	consumes := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, contentType := range contentTypes {
+		consumes.Content = append(consumes.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: contentType})
+	}
+	if existing := mappingValue(post, "consumes"); existing != nil {
+		*existing = *consumes
+	} else {
+		post.Content = append(post.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "consumes"}, consumes)
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// mappingValue returns the value of the key in the YAML mapping, or in the document it is the root of.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
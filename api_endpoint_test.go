@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAPIEndpointConsumesConfiguredContentTypes(t *testing.T) {
+	contentTypes := []string{"application/vnd.ft-upp-article+json", "application/vnd.ft-upp-audio+json"}
+	e, err := newAPIEndpoint("_ft/api.yml", func() []string { return contentTypes })
+	assert.NoError(t, err)
+
+	assert.Equal(t, contentTypes, servedConsumes(t, e))
+
+	contentTypes = []string{"application/vnd.ft-upp-video+json"}
+	assert.Equal(t, contentTypes, servedConsumes(t, e), "the content types are generated again after a reload")
+}
+
+func TestAPIEndpointWithoutConsumingPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yml")
+	if err := os.WriteFile(path, []byte("swagger: \"2.0\"\npaths: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := newAPIEndpoint(path, func() []string { return nil })
+	assert.EqualError(t, err, "no POST /drafts/content/suggestions in the API specification")
+}
+
+func servedConsumes(t *testing.T, e *apiEndpoint) []string {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__api", nil))
+	body, err := io.ReadAll(w.Body)
+	assert.NoError(t, err)
+
+	var spec struct {
+		Paths map[string]struct {
+			Post struct {
+				Consumes []string `yaml:"consumes"`
+			} `yaml:"post"`
+		} `yaml:"paths"`
+	}
+	assert.NoError(t, yaml.Unmarshal(body, &spec))
+	return spec.Paths["/drafts/content/suggestions"].Post.Consumes
+}
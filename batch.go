@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+)
+
+type batchRequest struct {
+	Items []batchItem `json:"items"`
+}
+
+// batchItem refers either to a draft by its uuid, or carries the content itself along with its content type.
+type batchItem struct {
+	UUID        string          `json:"uuid,omitempty"`
+	ContentType string          `json:"contentType,omitempty"`
+	Content     json.RawMessage `json:"content,omitempty"`
+}
+
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+}
+
+type batchResult struct {
+	UUID        string                    `json:"uuid,omitempty"`
+	Status      int                       `json:"status"`
+	Type        string                    `json:"type,omitempty"`
+	Message     string                    `json:"message,omitempty"`
+	Suggestions *[]suggestions.Suggestion `json:"suggestions,omitempty"`
+	// Problem is the problem details the single-draft endpoints would have responded with for the item.
+	Problem *problem `json:"problem,omitempty"`
+}
+
+// batchHandler provides suggestions for several drafts in a single request.
+// Every item gets its own result, so that a failing item does not fail the whole batch.
+type batchHandler struct {
+	rh          *requestHandler
+	concurrency int
+	maxItems    int
+}
+
+func (bh *batchHandler) batchSuggestionsRequest(writer http.ResponseWriter, request *http.Request) {
+	log := bh.rh.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(request))
+
+	filter, err := suggestions.NewFilterFromQuery(request.URL.Query())
+	if err != nil {
+		msg := "Invalid suggestions filter"
+		log.WithError(err).Warn(msg)
+		_ = bh.rh.writeProblem(writer, request, newProblem(problemInvalidFilter, fmt.Sprintf("%s: %s", msg, err.Error())))
+		return
+	}
+
+	var batch batchRequest
+	err = json.NewDecoder(request.Body).Decode(&batch)
+	if err != nil {
+		msg := "error while unmarshalling the batch request payload"
+		log.WithError(err).Warn(msg)
+		_ = bh.rh.writeProblem(writer, request, newProblem(problemInvalidBatch, msg))
+		return
+	}
+	if len(batch.Items) == 0 {
+		msg := "batch request has no items"
+		log.Warn(msg)
+		_ = bh.rh.writeProblem(writer, request, newProblem(problemInvalidBatch, msg))
+		return
+	}
+	if len(batch.Items) > bh.maxItems {
+		msg := fmt.Sprintf("batch request has more than %d items", bh.maxItems)
+		log.Warn(msg)
+		_ = bh.rh.writeProblem(writer, request, newProblem(problemInvalidBatch, msg))
+		return
+	}
+
+	ctx := NewContextFromRequest(request)
+	results := make([]batchResult, len(batch.Items))
+	sem := make(chan struct{}, bh.concurrency)
+	var wg sync.WaitGroup
+
+launch:
+	for i, item := range batch.Items {
+		// once the client has gone away, the remaining items are not worth processing
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break launch
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, item batchItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = bh.process(ctx, item, filter)
+		}(i, item)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		log.WithError(err).Warn("Batch suggestions request was cancelled before all its items were processed")
+		return
+	}
+
+	writer.Header().Set(contentTypeHeader, "application/json")
+	err = json.NewEncoder(writer).Encode(&batchResponse{Results: results})
+	if err != nil {
+		// could be related to intermittent/temporary network issues
+		// or original Tagme request is no more waiting for a response.
+		log.WithError(err).Error("Failed responding to batch suggestions request")
+	}
+}
+
+func (bh *batchHandler) process(ctx context.Context, item batchItem, filter suggestions.Filter) batchResult {
+	tid, _ := tidutils.GetTransactionIDFromContext(ctx)
+	log := bh.rh.log.WithTransactionID(tid)
+
+	var resp *suggestions.SuggestionsResponse
+	var prob *problem
+
+	switch {
+	case len(item.Content) > 0:
+		var baseContent BaseContent
+		_ = json.Unmarshal(item.Content, &baseContent)
+		uuid := item.UUID
+		if uuid == "" {
+			uuid = baseContent.UUID
+		} else if baseContent.UUID != "" && !strings.EqualFold(baseContent.UUID, uuid) {
+			msg := fmt.Sprintf("batch item uuid %s does not match the uuid %s of its content", uuid, baseContent.UUID)
+			log.WithUUID(uuid).Warn(msg)
+			return problemResult(uuid, newProblem(problemInvalidUUID, msg), tid)
+		}
+		if err := ValidateUUID(uuid); err != nil {
+			return problemResult(uuid, newProblem(problemInvalidUUID, "Invalid payload UUID"), tid)
+		}
+		resp, prob = bh.rh.fetchContentSuggestions(ctx, item.Content, uuid, item.ContentType, log.WithUUID(uuid))
+		item.UUID = uuid
+	case item.UUID != "":
+		if err := ValidateUUID(item.UUID); err != nil {
+			return problemResult(item.UUID, newProblem(problemInvalidUUID, "Invalid UUID"), tid)
+		}
+		_, resp, prob = bh.rh.fetchDraftSuggestions(ctx, item.UUID, log.WithUUID(item.UUID))
+	default:
+		return problemResult("", newProblem(problemInvalidBatch, "batch item needs either a uuid or a content"), tid)
+	}
+
+	if prob != nil {
+		return problemResult(item.UUID, prob, tid)
+	}
+
+	filtered := filter.Apply(resp).Suggestions
+	if filtered == nil {
+		filtered = []suggestions.Suggestion{}
+	}
+	return batchResult{UUID: item.UUID, Status: http.StatusOK, Suggestions: &filtered}
+}
+
+// problemResult embeds the whole problem in the result of the item, such as the field errors of the validator,
+// along with the transaction ID of the batch.
+func problemResult(uuid string, p *problem, tid string) batchResult {
+	detailed := *p
+	detailed.TransactionID = tid
+	return batchResult{UUID: uuid, Status: p.Status, Type: p.Type, Message: p.Detail, Problem: &detailed}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	transactionidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Financial-Times/draft-content-suggestions/draft"
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+)
+
+const (
+	batchFoundUUID   = "36320eb6-5617-4d12-9750-1907690e74db"
+	batchMissingUUID = "711e5bc1-3470-4297-ae26-154f145a6287"
+	batchInlineUUID  = "88db6314-45e1-45c9-898f-d98e2ff60967"
+)
+
+func newBatchTestServer(contentAPI draft.ContentAPI, umbrellaAPI suggestions.UmbrellaAPI, concurrency int, maxItems int) *httptest.Server {
+	rh := &requestHandler{dca: contentAPI, sua: umbrellaAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+	bh := &batchHandler{rh: rh, concurrency: concurrency, maxItems: maxItems}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/suggestions/batch", bh.batchSuggestionsRequest).Methods("POST")
+	return httptest.NewServer(r)
+}
+
+func postBatch(t *testing.T, url string, query string, payload string) (int, batchResponse) {
+	resp, err := http.Post(url+"/drafts/content/suggestions/batch"+query, "application/json", bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var batch batchResponse
+	if resp.StatusCode == http.StatusOK {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	}
+	return resp.StatusCode, batch
+}
+
+func TestBatchSuggestionsRequest(t *testing.T) {
+	foundContent := []byte(`{"uuid": "` + batchFoundUUID + `"}`)
+	inlineContent := `{"uuid":"` + batchInlineUUID + `","title":"Invesco launches first green building ETF"}`
+	validatedContent := []byte(`{"uuid": "` + batchInlineUUID + `", "validated": true}`)
+
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, batchFoundUUID).Return(foundContent, nil)
+	contentAPI.On("FetchDraftContent", mock.Anything, batchMissingUUID).Return([]byte(nil), nil)
+	contentAPI.On("FetchValidatedContent", mock.Anything, mock.Anything, batchInlineUUID, "application/vnd.ft-upp-article+json", mock.Anything).Return(validatedContent, nil)
+	contentAPI.On("FetchValidatedContent", mock.Anything, mock.Anything, batchInlineUUID, "text/plain", mock.Anything).Return([]byte(nil), fmt.Errorf("%w: no validator configured for contentType: text/plain", draft.ErrDraftContentTypeNotSupported))
+
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, foundContent).Return(&suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{
+		{ID: "http://www.ft.com/thing/1", Predicate: "http://www.ft.com/ontology/annotation/about"},
+		{ID: "http://www.ft.com/thing/2", Predicate: "http://www.ft.com/ontology/annotation/mentions"},
+	}}, nil)
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, validatedContent).Return(&suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{
+		{ID: "http://www.ft.com/thing/3", Predicate: "http://www.ft.com/ontology/annotation/mentions"},
+	}}, nil)
+
+	ts := newBatchTestServer(contentAPI, umbrellaAPI, 2, 10)
+	defer ts.Close()
+
+	status, batch := postBatch(t, ts.URL, "?predicate=about", `{"items": [
+		{"uuid": "`+batchFoundUUID+`"},
+		{"uuid": "`+batchMissingUUID+`"},
+		{"contentType": "application/vnd.ft-upp-article+json", "content": `+inlineContent+`},
+		{"contentType": "text/plain", "content": `+inlineContent+`},
+		{"uuid": "not-a-uuid"},
+		{}
+	]}`)
+
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, batch.Results, 6) {
+		assert.Equal(t, batchFoundUUID, batch.Results[0].UUID)
+		assert.Equal(t, http.StatusOK, batch.Results[0].Status)
+		if assert.NotNil(t, batch.Results[0].Suggestions) {
+			assert.Len(t, *batch.Results[0].Suggestions, 1)
+		}
+
+		assert.Equal(t, batchMissingUUID, batch.Results[1].UUID)
+		assert.Equal(t, http.StatusNotFound, batch.Results[1].Status)
+		assert.Equal(t, "urn:draft-content-suggestions:problem:draft-not-found", batch.Results[1].Type)
+		assert.Nil(t, batch.Results[1].Suggestions)
+
+		assert.Equal(t, batchInlineUUID, batch.Results[2].UUID)
+		assert.Equal(t, http.StatusOK, batch.Results[2].Status)
+		if assert.NotNil(t, batch.Results[2].Suggestions) {
+			assert.Empty(t, *batch.Results[2].Suggestions, "filtered out by the predicate query parameter")
+		}
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, batch.Results[3].Status)
+		assert.Contains(t, batch.Results[3].Message, "failed while validating content")
+
+		assert.Equal(t, http.StatusBadRequest, batch.Results[4].Status)
+		assert.Equal(t, http.StatusBadRequest, batch.Results[5].Status)
+	}
+}
+
+func TestBatchSuggestionsRequestBoundedConcurrency(t *testing.T) {
+	content := []byte(`{"uuid": "` + batchFoundUUID + `"}`)
+	var inFlight, maxInFlight int32
+
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, batchFoundUUID).Run(func(mock.Arguments) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}).Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil)
+
+	ts := newBatchTestServer(contentAPI, umbrellaAPI, 2, 10)
+	defer ts.Close()
+
+	item := `{"uuid": "` + batchFoundUUID + `"}`
+	status, batch := postBatch(t, ts.URL, "", `{"items": [`+item+`,`+item+`,`+item+`,`+item+`,`+item+`,`+item+`]}`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, batch.Results, 6)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestBatchSuggestionsRequestStopsWhenCancelled(t *testing.T) {
+	contentAPI := &draft.MockDraftContentAPI{}
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	rh := &requestHandler{dca: contentAPI, sua: umbrellaAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+	bh := &batchHandler{rh: rh, concurrency: 1, maxItems: 10}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	item := `{"uuid": "` + batchFoundUUID + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/drafts/content/suggestions/batch", bytes.NewReader([]byte(`{"items": [`+item+`,`+item+`]}`))).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	bh.batchSuggestionsRequest(w, req)
+
+	contentAPI.AssertNotCalled(t, "FetchDraftContent", mock.Anything, mock.Anything)
+	assert.Empty(t, w.Body.String(), "no one is waiting for the results")
+}
+
+func TestBatchSuggestionsRequestDoesNotCachePartialSuggestions(t *testing.T) {
+	content := []byte(`{"uuid": "` + batchFoundUUID + `"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, batchFoundUUID).Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{{ID: "id", Predicate: "about"}}}, nil)
+	extractorAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	extractorAPI.On("FetchSuggestions", mock.Anything, content).Return((*suggestions.SuggestionsResponse)(nil), errors.New("boom"))
+
+	registry, err := suggestions.NewProviderRegistry([]suggestions.Provider{{Name: "umbrella", API: umbrellaAPI}, {Name: "extractor", API: extractorAPI}})
+	assert.NoError(t, err)
+	cachedAPI := suggestions.NewCachedUmbrellaAPI(registry, suggestions.NewLRUCache(10, time.Minute), metrics.NewRegistry())
+
+	ts := newBatchTestServer(contentAPI, cachedAPI, 1, 10)
+	defer ts.Close()
+
+	for i := 0; i < 2; i++ {
+		status, batch := postBatch(t, ts.URL, "", `{"items": [{"uuid": "`+batchFoundUUID+`"}]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, batch.Results, 1)
+	}
+	// the suggestions were missing the ones of the failed provider, so the providers are called again
+	extractorAPI.AssertNumberOfCalls(t, "FetchSuggestions", 2)
+	umbrellaAPI.AssertNumberOfCalls(t, "FetchSuggestions", 2)
+}
+
+func TestBatchSuggestionsRequestEmbedsTheProblemOfEveryItem(t *testing.T) {
+	validatorResponse := `{"error":"schema validation has failed","errors":[{"field":"title","message":"title is required"}]}`
+	validatorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(validatorResponse))
+	}))
+	defer validatorServer.Close()
+
+	resolver := draft.NewContentValidatorResolver(map[string]draft.ContentValidator{
+		"application/vnd.ft-upp-article+json": draft.NewDraftContentValidatorService(validatorServer.URL, http.DefaultClient),
+	})
+	contentAPI, err := draft.NewContentAPI("http://localhost/drafts/content", "http://localhost/__gtg", http.DefaultClient, http.DefaultClient, resolver)
+	assert.NoError(t, err)
+	ts := newBatchTestServer(contentAPI, &suggestions.MockSuggestionsUmbrellaAPI{}, 2, 10)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/drafts/content/suggestions/batch", bytes.NewReader([]byte(`{"items": [
+		{"contentType": "application/vnd.ft-upp-article+json", "content": {"uuid": "`+batchInlineUUID+`"}},
+		{"uuid": "`+batchFoundUUID+`", "contentType": "application/vnd.ft-upp-article+json", "content": {"uuid": "`+batchInlineUUID+`"}}
+	]}`)))
+	assert.NoError(t, err)
+	req.Header.Set(transactionidutils.TransactionIDHeader, "tid_batch")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var batch batchResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	if assert.Len(t, batch.Results, 2) {
+		assert.Equal(t, http.StatusUnprocessableEntity, batch.Results[0].Status)
+		if assert.NotNil(t, batch.Results[0].Problem) {
+			assert.Equal(t, "urn:draft-content-suggestions:problem:draft-not-mappable", batch.Results[0].Problem.Type)
+			assert.Equal(t, "tid_batch", batch.Results[0].Problem.TransactionID)
+			assert.JSONEq(t, validatorResponse, string(batch.Results[0].Problem.Validator))
+			assert.Equal(t, []draft.FieldError{{Field: "title", Message: "title is required"}}, batch.Results[0].Problem.Errors)
+		}
+
+		assert.Equal(t, batchFoundUUID, batch.Results[1].UUID)
+		assert.Equal(t, http.StatusBadRequest, batch.Results[1].Status)
+		assert.Equal(t, "urn:draft-content-suggestions:problem:invalid-uuid", batch.Results[1].Type)
+		if assert.NotNil(t, batch.Results[1].Problem) {
+			assert.Contains(t, batch.Results[1].Problem.Detail, "does not match the uuid "+batchInlineUUID+" of its content")
+		}
+	}
+}
+
+func TestBatchSuggestionsRequestInvalidBatch(t *testing.T) {
+	ts := newBatchTestServer(&draft.MockDraftContentAPI{}, &suggestions.MockSuggestionsUmbrellaAPI{}, 2, 2)
+	defer ts.Close()
+
+	for _, payload := range []string{
+		``,
+		`{"items": []}`,
+		`{"items": [{}, {}, {}]}`,
+	} {
+		status, _ := postBatch(t, ts.URL, "", payload)
+		assert.Equal(t, http.StatusBadRequest, status, payload)
+	}
+}
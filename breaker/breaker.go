@@ -0,0 +1,186 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// ErrOpen is returned, wrapped, for calls rejected because the circuit of their dependency is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State of a circuit breaker.
+type State int
+
+const (
+	// Closed lets every call through.
+	Closed State = iota
+	// HalfOpen lets a single trial call through to find out whether the dependency has recovered.
+	HalfOpen
+	// Open rejects every call until the open timeout elapses.
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Settings configure when a circuit breaker opens and for how long.
+type Settings struct {
+	// FailureThreshold is the number of consecutive failed calls opening the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a trial call is let through.
+	OpenTimeout time.Duration
+}
+
+// Breaker guards the calls to a single downstream dependency.
+// Transport errors and 5xx responses count as failures; any other response closes the circuit again.
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	stateGauge metrics.Gauge
+	rejected   metrics.Counter
+}
+
+// New returns a closed circuit breaker for the named dependency, exporting its state to the metrics registry.
+func New(name string, settings Settings, registry metrics.Registry) *Breaker {
+	b := &Breaker{
+		name:       name,
+		settings:   settings,
+		now:        time.Now,
+		stateGauge: metrics.GetOrRegisterGauge(fmt.Sprintf("circuit-breaker.%s.state", name), registry),
+		rejected:   metrics.GetOrRegisterCounter(fmt.Sprintf("circuit-breaker.%s.rejected", name), registry),
+	}
+	// the gauge is shared with the breaker this one may replace, such as the one of a validator moved to another
+	// endpoint
+	b.stateGauge.Update(int64(Closed))
+	return b
+}
+
+// Name returns the name of the dependency guarded by the breaker.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// NewClient returns a copy of the base client whose calls are guarded by the breaker.
+func NewClient(base *http.Client, b *Breaker) *http.Client {
+	client := *base
+	client.Transport = b.Transport(base.Transport)
+	return &client
+}
+
+// Transport wraps the next http.RoundTripper so that its calls are guarded by the breaker.
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := b.allow(); err != nil {
+			return nil, err
+		}
+
+		resp, err := next.RoundTrip(req)
+		switch {
+		case err != nil && req.Context().Err() != nil:
+			// the caller gave up, which tells nothing about the dependency
+			b.release()
+		case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+			b.onFailure()
+		default:
+			b.onSuccess()
+		}
+
+		return resp, err
+	})
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.setState(HalfOpen)
+	}
+
+	switch {
+	case b.state == Open, b.state == HalfOpen && b.probing:
+		b.rejected.Inc(1)
+		return fmt.Errorf("%w for %s", ErrOpen, b.name)
+	case b.state == HalfOpen:
+		b.probing = true
+	}
+
+	return nil
+}
+
+func (b *Breaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures = 0
+	b.setState(Closed)
+}
+
+func (b *Breaker) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.openedAt = b.now()
+		b.setState(Open)
+	}
+}
+
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.stateGauge.Update(int64(s))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,142 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(status *int32) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+	}))
+	return server, &calls
+}
+
+func get(client *http.Client, url string) (*http.Response, error) {
+	resp, err := client.Get(url)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	status := int32(http.StatusBadGateway)
+	server, calls := newTestServer(&status)
+	defer server.Close()
+
+	registry := metrics.NewRegistry()
+	b := New("umbrella", Settings{FailureThreshold: 3, OpenTimeout: time.Minute}, registry)
+	client := NewClient(http.DefaultClient, b)
+
+	for i := 0; i < 3; i++ {
+		resp, err := get(client, server.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	}
+	assert.Equal(t, Open, b.State())
+
+	_, err := get(client, server.URL)
+	assert.True(t, errors.Is(err, ErrOpen))
+	assert.Contains(t, err.Error(), "circuit breaker is open for umbrella")
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls), "an open circuit should not reach the dependency")
+
+	assert.Equal(t, int64(Open), registry.Get("circuit-breaker.umbrella.state").(metrics.Gauge).Value())
+	assert.Equal(t, int64(1), registry.Get("circuit-breaker.umbrella.rejected").(metrics.Counter).Count())
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	status := int32(http.StatusUnprocessableEntity)
+	server, _ := newTestServer(&status)
+	defer server.Close()
+
+	b := New("validator", Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, metrics.NewRegistry())
+	client := NewClient(http.DefaultClient, b)
+
+	for i := 0; i < 3; i++ {
+		_, err := get(client, server.URL)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	status := int32(http.StatusServiceUnavailable)
+	server, _ := newTestServer(&status)
+	defer server.Close()
+
+	b := New("draft-content", Settings{FailureThreshold: 2, OpenTimeout: time.Minute}, metrics.NewRegistry())
+	client := NewClient(http.DefaultClient, b)
+
+	_, _ = get(client, server.URL)
+	atomic.StoreInt32(&status, http.StatusOK)
+	_, _ = get(client, server.URL)
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	_, _ = get(client, server.URL)
+
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreakerHalfOpenTrialCall(t *testing.T) {
+	status := int32(http.StatusBadGateway)
+	server, calls := newTestServer(&status)
+	defer server.Close()
+
+	b := New("umbrella", Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, metrics.NewRegistry())
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	client := NewClient(http.DefaultClient, b)
+
+	_, _ = get(client, server.URL)
+	assert.Equal(t, Open, b.State())
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, HalfOpen, b.State())
+
+	// a failed trial call opens the circuit straight away
+	_, err := get(client, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, Open, b.State())
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+	now = now.Add(2 * time.Minute)
+	atomic.StoreInt32(&status, http.StatusOK)
+	_, err = get(client, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreakerRejectsConcurrentTrialCalls(t *testing.T) {
+	b := New("umbrella", Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, metrics.NewRegistry())
+	b.state = HalfOpen
+
+	assert.NoError(t, b.allow())
+	assert.True(t, errors.Is(b.allow(), ErrOpen))
+
+	b.onSuccess()
+	assert.NoError(t, b.allow())
+}
+
+func TestBreakerIgnoresCancelledCalls(t *testing.T) {
+	b := New("umbrella", Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, metrics.NewRegistry())
+	client := NewClient(http.DefaultClient, b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:1", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.Error(t, err)
+	assert.Equal(t, Closed, b.State())
+}
@@ -2,18 +2,31 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	ContentTypes map[string]ValidatorConfig   `yaml:"content-types"`
 	HealthChecks map[string]HealthCheckConfig `yaml:"end-point-health-checks"`
+
+	// source and positions locate the configuration entries when reporting validation problems
+	source    string
+	positions map[positionKey]Position
 }
 
 type ValidatorConfig struct {
-	Validator string `yaml:"validator"`
-	Endpoint  string `yaml:"end-point"`
+	// Aliases are the other content-types resolved to the same validator. Like the content-type itself, they can carry
+	// media-type parameters, e.g. application/json; profile=article, which then have to be part of the requests.
+	Aliases   []string `yaml:"aliases,omitempty"`
+	Validator string   `yaml:"validator"`
+	Endpoint  string   `yaml:"end-point,omitempty"`
+	// Schema is the JSON Schema file of the jsonschema validator, relative to the configuration file.
+	Schema string `yaml:"schema,omitempty"`
+	// Chain lists the validators of the chained validator, in the order the content goes through them.
+	Chain []ValidatorConfig `yaml:"chain,omitempty"`
 }
 
 type HealthCheckConfig struct {
@@ -27,16 +40,94 @@ type HealthCheckConfig struct {
 }
 
 func ReadConfig(yml string) (*Config, error) {
+	cfg, _, err := readConfigFile(yml)
+	return cfg, err
+}
+
+// readConfigFile reads and parses the configuration file, resolving its schema paths relative to it.
+// The raw content is returned along, for the watcher to tell whether the file has changed.
+func readConfigFile(yml string) (*Config, []byte, error) {
 	by, err := os.ReadFile(yml)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := ParseConfig(by)
+	if err != nil {
+		return nil, by, err
+	}
+	cfg.source = yml
+	cfg.resolveSchemaPaths(filepath.Dir(yml))
+
+	return cfg, by, nil
+}
+
+// ParseConfig parses the YAML content of a configuration file.
+func ParseConfig(by []byte) (*Config, error) {
+	var root yaml.Node
+	err := yaml.Unmarshal(by, &root)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := &Config{make(map[string]ValidatorConfig), make(map[string]HealthCheckConfig)}
-	err = yaml.Unmarshal(by, cfg)
+	cfg := &Config{
+		ContentTypes: make(map[string]ValidatorConfig),
+		HealthChecks: make(map[string]HealthCheckConfig),
+		positions:    make(map[positionKey]Position),
+	}
+	if len(root.Content) == 0 {
+		return cfg, nil
+	}
+
+	err = root.Decode(cfg)
 	if err != nil {
-		cfg = nil
+		return nil, err
 	}
+	cfg.recordPositions(root.Content[0])
 
-	return cfg, err
+	return cfg, nil
+}
+
+func (c *Config) resolveSchemaPaths(dir string) {
+	for contentType, cfg := range c.ContentTypes {
+		c.ContentTypes[contentType] = cfg.resolveSchemaPaths(dir)
+	}
+}
+
+func (v ValidatorConfig) resolveSchemaPaths(dir string) ValidatorConfig {
+	if v.Schema != "" && !filepath.IsAbs(v.Schema) {
+		v.Schema = filepath.Join(dir, v.Schema)
+	}
+	if len(v.Chain) > 0 {
+		chain := make([]ValidatorConfig, len(v.Chain))
+		for i, stage := range v.Chain {
+			chain[i] = stage.resolveSchemaPaths(dir)
+		}
+		v.Chain = chain
+	}
+	return v
+}
+
+// schemaPaths returns the schema files the validators refer to, sorted and without duplicates.
+func (c *Config) schemaPaths() []string {
+	found := map[string]bool{}
+	var collect func(v ValidatorConfig)
+	collect = func(v ValidatorConfig) {
+		if v.Schema != "" {
+			found[v.Schema] = true
+		}
+		for _, stage := range v.Chain {
+			collect(stage)
+		}
+	}
+	for _, cfg := range c.ContentTypes {
+		collect(cfg)
+	}
+
+	paths := make([]string, 0, len(found))
+	for path := range found {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
 }
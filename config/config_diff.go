@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Diff describes what changed from the old to the new configuration, one line per added, removed or changed entry.
+func Diff(old *Config, new *Config) []string {
+	var changes []string
+
+	for contentType, cfg := range new.ContentTypes {
+		oldCfg, found := old.ContentTypes[contentType]
+		switch {
+		case !found:
+			changes = append(changes, fmt.Sprintf("content-type %s added (validator: %s, end-point: %s)", contentType, cfg.Validator, cfg.Endpoint))
+		case !reflect.DeepEqual(oldCfg, cfg):
+			changes = append(changes, fmt.Sprintf("content-type %s changed (%s)", contentType, validatorChanges(oldCfg, cfg)))
+		}
+	}
+	for contentType := range old.ContentTypes {
+		if _, found := new.ContentTypes[contentType]; !found {
+			changes = append(changes, fmt.Sprintf("content-type %s removed", contentType))
+		}
+	}
+
+	for endpoint, cfg := range new.HealthChecks {
+		oldCfg, found := old.HealthChecks[endpoint]
+		switch {
+		case !found:
+			changes = append(changes, fmt.Sprintf("health check %s added (id: %s)", endpoint, cfg.ID))
+		case oldCfg != cfg:
+			changes = append(changes, fmt.Sprintf("health check %s changed (id: %s)", endpoint, cfg.ID))
+		}
+	}
+	for endpoint := range old.HealthChecks {
+		if _, found := new.HealthChecks[endpoint]; !found {
+			changes = append(changes, fmt.Sprintf("health check %s removed", endpoint))
+		}
+	}
+
+	sort.Strings(changes)
+	return changes
+}
+
+func validatorChanges(old ValidatorConfig, new ValidatorConfig) string {
+	var fields []string
+	if old.Validator != new.Validator {
+		fields = append(fields, fmt.Sprintf("validator: %s -> %s", old.Validator, new.Validator))
+	}
+	if old.Endpoint != new.Endpoint {
+		fields = append(fields, fmt.Sprintf("end-point: %s -> %s", old.Endpoint, new.Endpoint))
+	}
+	if !reflect.DeepEqual(old.Aliases, new.Aliases) {
+		fields = append(fields, fmt.Sprintf("aliases: [%s] -> [%s]", strings.Join(old.Aliases, ", "), strings.Join(new.Aliases, ", ")))
+	}
+	if old.Schema != new.Schema {
+		fields = append(fields, fmt.Sprintf("schema: %s -> %s", old.Schema, new.Schema))
+	}
+	if !reflect.DeepEqual(old.Chain, new.Chain) {
+		fields = append(fields, fmt.Sprintf("chain: [%s] -> [%s]", chainKinds(old.Chain), chainKinds(new.Chain)))
+	}
+	return strings.Join(fields, ", ")
+}
+
+func chainKinds(chain []ValidatorConfig) string {
+	kinds := make([]string, 0, len(chain))
+	for _, stage := range chain {
+		kinds = append(kinds, stage.Validator)
+	}
+	return strings.Join(kinds, ", ")
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		ContentTypes: map[string]ValidatorConfig{
+			"application/vnd.ft-upp-article+json":             {Validator: "generic", Endpoint: "http://upp-article-validator:8080"},
+			"application/vnd.ft-upp-content-placeholder+json": {Validator: "generic", Endpoint: "http://upp-content-placeholder-validator:8080"},
+		},
+		HealthChecks: map[string]HealthCheckConfig{
+			"http://upp-article-validator:8080":             {ID: "check-article"},
+			"http://upp-content-placeholder-validator:8080": {ID: "check-placeholder"},
+		},
+	}
+	new := &Config{
+		ContentTypes: map[string]ValidatorConfig{
+			"application/vnd.ft-upp-article+json":        {Validator: "generic", Endpoint: "http://upp-article-validator:9090"},
+			"application/vnd.ft-upp-live-blog-post+json": {Validator: "generic", Endpoint: "http://upp-live-blog-post-validator:8080"},
+		},
+		HealthChecks: map[string]HealthCheckConfig{
+			"http://upp-article-validator:9090":        {ID: "check-article"},
+			"http://upp-live-blog-post-validator:8080": {ID: "check-live-blog-post"},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"content-type application/vnd.ft-upp-article+json changed (end-point: http://upp-article-validator:8080 -> http://upp-article-validator:9090)",
+		"content-type application/vnd.ft-upp-content-placeholder+json removed",
+		"content-type application/vnd.ft-upp-live-blog-post+json added (validator: generic, end-point: http://upp-live-blog-post-validator:8080)",
+		"health check http://upp-article-validator:8080 removed",
+		"health check http://upp-article-validator:9090 added (id: check-article)",
+		"health check http://upp-content-placeholder-validator:8080 removed",
+		"health check http://upp-live-blog-post-validator:8080 added (id: check-live-blog-post)",
+	}, Diff(old, new))
+	assert.Empty(t, Diff(old, old))
+}
+
+func TestDiffChain(t *testing.T) {
+	old := &Config{ContentTypes: map[string]ValidatorConfig{
+		"application/vnd.ft-upp-article+json": {Validator: "chained", Chain: []ValidatorConfig{
+			{Validator: "generic", Endpoint: "http://upp-article-validator:8080"},
+		}},
+	}}
+	new := &Config{ContentTypes: map[string]ValidatorConfig{
+		"application/vnd.ft-upp-article+json": {Validator: "chained", Chain: []ValidatorConfig{
+			{Validator: "jsonschema", Schema: "schemas/article.json"},
+			{Validator: "generic", Endpoint: "http://upp-article-validator:8080"},
+		}},
+	}}
+
+	assert.Equal(t, []string{
+		"content-type application/vnd.ft-upp-article+json changed (chain: [generic] -> [jsonschema, generic])",
+	}, Diff(old, new))
+	assert.Empty(t, Diff(new, new))
+}
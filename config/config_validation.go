@@ -0,0 +1,299 @@
+package config
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Financial-Times/draft-content-suggestions/endpointessentials"
+)
+
+const (
+	contentTypesKey = "content-types"
+	healthChecksKey = "end-point-health-checks"
+	chainKey        = "chain"
+	aliasesKey      = "aliases"
+
+	// ValidatorGeneric is the validator kind posting the content to an UPP validator service.
+	ValidatorGeneric = "generic"
+	// ValidatorJSONSchema is the validator kind checking the content against a local JSON Schema file.
+	ValidatorJSONSchema = "jsonschema"
+	// ValidatorPassthrough is the validator kind returning the content of trusted producers unchanged.
+	ValidatorPassthrough = "passthrough"
+	// ValidatorChained is the validator kind piping the content through the validators of its chain, in order.
+	ValidatorChained = "chained"
+)
+
+var validatorKinds = []string{ValidatorGeneric, ValidatorJSONSchema, ValidatorPassthrough, ValidatorChained}
+
+// Position is the line and column of an entry in the configuration file.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Problem is a single problem found in the configuration.
+type Problem struct {
+	Source   string
+	Position Position
+	Message  string
+}
+
+func (p Problem) Error() string {
+	if p.Position.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.Source, p.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", p.Source, p.Position.Line, p.Position.Column, p.Message)
+}
+
+// ValidationError lists every problem found in the configuration.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		lines = append(lines, p.Error())
+	}
+	return fmt.Sprintf("invalid configuration, %d problem(s) found:\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// Validate checks the whole configuration, and returns a *ValidationError listing all the problems found, if any.
+func (c *Config) Validate() error {
+	v := &validation{config: c}
+
+	v.validateMediaTypes()
+
+	endpoints := map[string]bool{}
+	for contentType, cfg := range c.ContentTypes {
+		if cfg.Validator != ValidatorChained {
+			v.validateValidator(contentType, contentType, "", cfg, endpoints)
+			continue
+		}
+
+		if len(cfg.Chain) == 0 {
+			v.report(positionKey{contentTypesKey, contentType, chainKey}, "chained validator of content-type %s has no chain", contentType)
+		}
+		for i, stage := range cfg.Chain {
+			name := fmt.Sprintf("%s (chain stage %d)", contentType, i+1)
+			prefix := fmt.Sprintf("chain[%d].", i)
+			if stage.Validator == ValidatorChained {
+				v.report(positionKey{contentTypesKey, contentType, prefix + "validator"}, "chained validator of content-type %s cannot be nested", name)
+				continue
+			}
+			v.validateValidator(contentType, name, prefix, stage, endpoints)
+		}
+	}
+
+	endpointsByID := map[string][]string{}
+	for endpoint, cfg := range c.HealthChecks {
+		if !endpoints[endpoint] {
+			v.report(positionKey{healthChecksKey, endpoint, ""}, "health check %s matches the end-point of no content-type", endpoint)
+		}
+		if !strings.Contains(cfg.TechnicalSummary, "%v") {
+			v.report(positionKey{healthChecksKey, endpoint, "technical-summary"}, "technical-summary of health check %s has no %%v for the end-point", endpoint)
+		}
+		endpointsByID[cfg.ID] = append(endpointsByID[cfg.ID], endpoint)
+	}
+	for id, endpoints := range endpointsByID {
+		sort.Slice(endpoints, func(i, j int) bool {
+			return v.position(positionKey{healthChecksKey, endpoints[i], ""}).Line < v.position(positionKey{healthChecksKey, endpoints[j], ""}).Line
+		})
+		for _, endpoint := range endpoints[1:] {
+			v.report(positionKey{healthChecksKey, endpoint, "id"}, "health check id %q is already used by %s", id, endpoints[0])
+		}
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		pi, pj := v.problems[i].Position, v.problems[j].Position
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		if pi.Column != pj.Column {
+			return pi.Column < pj.Column
+		}
+		return v.problems[i].Message < v.problems[j].Message
+	})
+	return &ValidationError{Problems: v.problems}
+}
+
+// validateMediaTypes checks that the content-types and their aliases are media types, and that no media type is
+// resolved to two validators.
+func (v *validation) validateMediaTypes() {
+	type use struct {
+		key         positionKey
+		contentType string
+		mediaType   string
+	}
+
+	var uses []use
+	for contentType, cfg := range v.config.ContentTypes {
+		uses = append(uses, use{positionKey{contentTypesKey, contentType, ""}, contentType, contentType})
+		for i, alias := range cfg.Aliases {
+			uses = append(uses, use{positionKey{contentTypesKey, contentType, fmt.Sprintf("%s[%d]", aliasesKey, i)}, contentType, alias})
+		}
+	}
+	sort.Slice(uses, func(i, j int) bool {
+		pi, pj := v.position(uses[i].key), v.position(uses[j].key)
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return uses[i].mediaType < uses[j].mediaType
+	})
+
+	seen := map[string]string{}
+	for _, u := range uses {
+		normalized, err := NormalizeMediaType(u.mediaType)
+		if err != nil {
+			v.report(u.key, "invalid media type %q for content-type %s: %s", u.mediaType, u.contentType, err.Error())
+			continue
+		}
+		if other, found := seen[normalized]; found {
+			v.report(u.key, "media type %q of content-type %s is already resolved to content-type %s", u.mediaType, u.contentType, other)
+			continue
+		}
+		seen[normalized] = u.contentType
+	}
+}
+
+// NormalizeMediaType returns the canonical form of a media type, so that two spellings of it can be compared.
+func NormalizeMediaType(mediaType string) (string, error) {
+	base, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", err
+	}
+	return mime.FormatMediaType(base, params), nil
+}
+
+// validateValidator checks a validator of a content-type, either its own or a stage of its chain whose fields are prefixed,
+// and collects the end-points of the remote ones.
+func (v *validation) validateValidator(contentType string, name string, prefix string, cfg ValidatorConfig, endpoints map[string]bool) {
+	key := func(field string) positionKey {
+		return positionKey{contentTypesKey, contentType, prefix + field}
+	}
+
+	switch cfg.Validator {
+	case ValidatorJSONSchema:
+		if cfg.Schema == "" {
+			v.report(key("schema"), "missing schema for the jsonschema validator of content-type %s", name)
+		} else if _, err := os.Stat(cfg.Schema); err != nil {
+			v.report(key("schema"), "unreadable schema for content-type %s: %s", name, err.Error())
+		}
+		return
+	case ValidatorPassthrough:
+		return
+	case ValidatorGeneric:
+	default:
+		// an unknown validator is most likely a misspelt remote one, whose end-point is still worth checking
+		v.report(key("validator"), "unknown validator %q for content-type %s, expected one of: %s",
+			cfg.Validator, name, strings.Join(validatorKinds, ", "))
+	}
+
+	if err := endpointessentials.ValidateEndpoint(cfg.Endpoint); err != nil {
+		v.report(key("end-point"), "invalid end-point for content-type %s: %s", name, strings.TrimSpace(err.Error()))
+	}
+	endpoints[cfg.Endpoint] = true
+}
+
+// positionKey identifies an entry of the configuration, or one of its fields.
+type positionKey struct {
+	section string
+	entry   string
+	field   string
+}
+
+type validation struct {
+	config   *Config
+	problems []Problem
+}
+
+func (v *validation) position(key positionKey) Position {
+	// a missing field is reported where its entry, or its chain stage, is
+	if pos, found := v.config.positions[key]; found {
+		return pos
+	}
+	if i := strings.LastIndex(key.field, "."); i >= 0 {
+		if pos, found := v.config.positions[positionKey{key.section, key.entry, key.field[:i]}]; found {
+			return pos
+		}
+	}
+	if pos, found := v.config.positions[positionKey{key.section, key.entry, ""}]; found {
+		return pos
+	}
+	return v.config.positions[positionKey{key.section, "", ""}]
+}
+
+func (v *validation) report(key positionKey, format string, args ...interface{}) {
+	source := v.config.source
+	if source == "" {
+		source = "config"
+	}
+	v.problems = append(v.problems, Problem{
+		Source:   source,
+		Position: v.position(key),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// recordPositions remembers where every content-type and health check, and each of their fields, are in the file.
+func (c *Config) recordPositions(root *yaml.Node) {
+	if root.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		section, entries := root.Content[i], root.Content[i+1]
+		if section.Value != contentTypesKey && section.Value != healthChecksKey {
+			continue
+		}
+		c.positions[positionKey{section.Value, "", ""}] = Position{section.Line, section.Column}
+		if entries.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for j := 0; j+1 < len(entries.Content); j += 2 {
+			entry, fields := entries.Content[j], entries.Content[j+1]
+			c.positions[positionKey{section.Value, entry.Value, ""}] = Position{entry.Line, entry.Column}
+			if fields.Kind != yaml.MappingNode {
+				continue
+			}
+
+			for k := 0; k+1 < len(fields.Content); k += 2 {
+				field, value := fields.Content[k], fields.Content[k+1]
+				c.positions[positionKey{section.Value, entry.Value, field.Value}] = Position{value.Line, value.Column}
+				if field.Value == chainKey && value.Kind == yaml.SequenceNode {
+					c.recordChainPositions(section.Value, entry.Value, value)
+				}
+				if field.Value == aliasesKey && value.Kind == yaml.SequenceNode {
+					for i, alias := range value.Content {
+						c.positions[positionKey{section.Value, entry.Value, fmt.Sprintf("%s[%d]", aliasesKey, i)}] = Position{alias.Line, alias.Column}
+					}
+				}
+			}
+		}
+	}
+}
+
+// recordChainPositions remembers where every stage of a chain, and each of their fields, are, as chain[i] and chain[i].field.
+func (c *Config) recordChainPositions(section string, entry string, chain *yaml.Node) {
+	for i, stage := range chain.Content {
+		prefix := fmt.Sprintf("%s[%d]", chainKey, i)
+		c.positions[positionKey{section, entry, prefix}] = Position{stage.Line, stage.Column}
+		if stage.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for k := 0; k+1 < len(stage.Content); k += 2 {
+			field, value := stage.Content[k], stage.Content[k+1]
+			c.positions[positionKey{section, entry, prefix + "." + field.Value}] = Position{value.Line, value.Column}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateShippedConfigs(t *testing.T) {
+	for _, path := range []string{"../config.yml", "../config.local.yml", "../config.dredd.yml"} {
+		cfg, err := ReadConfig(path)
+		if assert.NoError(t, err, path) {
+			assert.NoError(t, cfg.Validate(), path)
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte(`content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "genric"
+    end-point: "http://upp-article-validator:8080"
+  "application/vnd.ft-upp-content-placeholder+json":
+    validator: "generic"
+    end-point: "upp-content-placeholder-validator:8080"
+end-point-health-checks:
+  "http://upp-article-validator:8080":
+    id: "check-draft-validator"
+    technical-summary: "Draft upp article validator is not available at %v"
+  "http://upp-live-blog-post-validator:8080":
+    id: "check-draft-validator"
+    technical-summary: "Live blog post content validator is not available"
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadConfig(path)
+	assert.NoError(t, err)
+
+	err = cfg.Validate()
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		messages := make([]string, 0, len(validationErr.Problems))
+		for _, p := range validationErr.Problems {
+			messages = append(messages, p.Error())
+		}
+		assert.Equal(t, []string{
+			path + `:3:16: unknown validator "genric" for content-type application/vnd.ft-upp-article+json, expected one of: generic, jsonschema, passthrough, chained`,
+			path + ":7:16: invalid end-point for content-type application/vnd.ft-upp-content-placeholder+json: missing scheme in endpoint: upp-content-placeholder-validator:8080",
+			path + ":12:3: health check http://upp-live-blog-post-validator:8080 matches the end-point of no content-type",
+			path + `:13:9: health check id "check-draft-validator" is already used by http://upp-article-validator:8080`,
+			path + ":14:24: technical-summary of health check http://upp-live-blog-post-validator:8080 has no %v for the end-point",
+		}, messages)
+	}
+	assert.Contains(t, err.Error(), "invalid configuration, 5 problem(s) found:")
+}
+
+func TestValidateMissingFieldIsReportedAtItsEntry(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`content-types:
+  "application/vnd.ft-upp-article+json":
+    end-point: "http://upp-article-validator:8080"
+`))
+	assert.NoError(t, err)
+
+	assert.EqualError(t, cfg.Validate(), "invalid configuration, 1 problem(s) found:\n"+
+		`config:2:3: unknown validator "" for content-type application/vnd.ft-upp-article+json, expected one of: generic, jsonschema, passthrough, chained`)
+}
+
+func TestValidateJSONSchemaValidator(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	err := os.WriteFile(path, []byte(`content-types:
+  "application/vnd.ft-upp-audio+json":
+    validator: "jsonschema"
+    schema: "schemas/audio.json"
+  "application/vnd.ft-upp-video+json":
+    validator: "jsonschema"
+  "application/vnd.ft-upp-event+json":
+    validator: "jsonschema"
+    schema: "schemas/missing.json"
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "schemas"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schemas", "audio.json"), []byte(`{"type": "object"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "schemas", "audio.json"), cfg.ContentTypes["application/vnd.ft-upp-audio+json"].Schema,
+		"the schema is relative to the configuration file")
+
+	err = cfg.Validate()
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) && assert.Len(t, validationErr.Problems, 2) {
+		assert.Equal(t, path+":5:3: missing schema for the jsonschema validator of content-type application/vnd.ft-upp-video+json",
+			validationErr.Problems[0].Error())
+		assert.Contains(t, validationErr.Problems[1].Error(), path+":9:13: unreadable schema for content-type application/vnd.ft-upp-event+json")
+	}
+}
+
+func TestValidatePassthroughValidatorNeedsNoEndpoint(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "passthrough"
+`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateChainedValidator(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "chained"
+    chain:
+      - validator: "passthrough"
+      - validator: "generic"
+        end-point: "upp-article-validator:8080"
+      - validator: "chained"
+      - end-point: "http://upp-article-validator:8080"
+  "application/vnd.ft-upp-content-placeholder+json":
+    validator: "chained"
+end-point-health-checks:
+  "http://upp-article-validator:8080":
+    id: "check-draft-upp-article-validator"
+    technical-summary: "Draft upp article validator is not available at %v"
+`))
+	assert.NoError(t, err)
+
+	err = cfg.Validate()
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		messages := make([]string, 0, len(validationErr.Problems))
+		for _, p := range validationErr.Problems {
+			messages = append(messages, p.Error())
+		}
+		assert.Equal(t, []string{
+			"config:7:20: invalid end-point for content-type application/vnd.ft-upp-article+json (chain stage 2): missing scheme in endpoint: upp-article-validator:8080",
+			"config:8:20: chained validator of content-type application/vnd.ft-upp-article+json (chain stage 3) cannot be nested",
+			`config:9:9: unknown validator "" for content-type application/vnd.ft-upp-article+json (chain stage 4), expected one of: generic, jsonschema, passthrough, chained`,
+			"config:10:3: chained validator of content-type application/vnd.ft-upp-content-placeholder+json has no chain",
+		}, messages, "the end-point of a stage matches its health check")
+	}
+}
+
+func TestValidateAliases(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`content-types:
+  "application/vnd.ft-upp-article+json":
+    aliases:
+      - "application/vnd.ft-upp-article-internal+json"
+      - "application/json; profile=article"
+    validator: "passthrough"
+  "application/json; profile=article":
+    validator: "passthrough"
+  "application/vnd.ft-upp-live-blog-post+json":
+    aliases: ["application/json; profile=", "Application/VND.FT-UPP-Article-Internal+JSON"]
+    validator: "passthrough"
+`))
+	assert.NoError(t, err)
+
+	err = cfg.Validate()
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		messages := make([]string, 0, len(validationErr.Problems))
+		for _, p := range validationErr.Problems {
+			messages = append(messages, p.Error())
+		}
+		assert.Equal(t, []string{
+			`config:7:3: media type "application/json; profile=article" of content-type application/json; profile=article is already resolved to content-type application/vnd.ft-upp-article+json`,
+			`config:10:15: invalid media type "application/json; profile=" for content-type application/vnd.ft-upp-live-blog-post+json: mime: invalid media parameter`,
+			`config:10:45: media type "Application/VND.FT-UPP-Article-Internal+JSON" of content-type application/vnd.ft-upp-live-blog-post+json is already resolved to content-type application/vnd.ft-upp-article+json`,
+		}, messages)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+)
+
+// ReloadFunc applies a new configuration. Returning an error rejects it, and the current configuration stays in use.
+type ReloadFunc func(cfg *Config) error
+
+// Watcher reloads the configuration file whenever its content or the content of the schema files it refers to
+// changes, or when the process receives a SIGHUP.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	reload   ReloadFunc
+	log      *logger.UPPLogger
+
+	mu      sync.Mutex
+	current *Config
+	hash    [sha256.Size]byte
+}
+
+// NewWatcher returns a Watcher of the configuration file at path, currently applied as current.
+// The file is polled every interval, and only on SIGHUP when the interval is not positive.
+func NewWatcher(path string, current *Config, interval time.Duration, reload ReloadFunc, log *logger.UPPLogger) *Watcher {
+	w := &Watcher{
+		path:     path,
+		interval: interval,
+		reload:   reload,
+		log:      log,
+		current:  current,
+	}
+	// the files are only known to be applied already if they still hold the current configuration
+	if cfg, by, err := readConfigFile(path); err == nil && len(Diff(current, cfg)) == 0 {
+		w.hash = contentHash(cfg, by)
+	}
+	return w
+}
+
+// Current returns the last configuration successfully applied.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Run watches the configuration file until the context is done.
+func (w *Watcher) Run(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			_ = w.check(false)
+		case <-sighup:
+			w.log.WithField("path", w.path).Info("Received SIGHUP, reloading validator configuration")
+			_ = w.check(true)
+		}
+	}
+}
+
+// Reload reads the configuration file and applies it, even when neither it nor its schema files have changed.
+func (w *Watcher) Reload() error {
+	return w.check(true)
+}
+
+func (w *Watcher) check(force bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg, by, err := readConfigFile(w.path)
+	if by == nil {
+		w.log.WithError(err).WithField("path", w.path).Error("Unable to read validator configuration, keeping the current one")
+		return err
+	}
+	hash := contentHash(cfg, by)
+	if hash == w.hash && !force {
+		return nil
+	}
+	// a rejected file is not retried until it changes again, so that polling does not log the same error over and over
+	w.hash = hash
+
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		w.log.WithError(err).WithField("path", w.path).Error("Invalid validator configuration, keeping the current one")
+		return err
+	}
+
+	// a configuration without changes is still applied, as the schema files it refers to may have changed
+	changes := Diff(w.current, cfg)
+	if err := w.reload(cfg); err != nil {
+		w.log.WithError(err).WithField("path", w.path).WithField("changes", changes).Error("Rejected validator configuration, keeping the current one")
+		return err
+	}
+	w.current = cfg
+	w.log.WithField("path", w.path).WithField("changes", changes).Info("Reloaded validator configuration")
+
+	return nil
+}
+
+// contentHash covers the configuration file and the schema files it refers to, so that editing a schema is picked up
+// like editing the configuration file itself.
+func contentHash(cfg *Config, by []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(by)
+	if cfg != nil {
+		for _, path := range cfg.schemaPaths() {
+			// a schema which cannot be read is reported by the validation of the configuration
+			schema, _ := os.ReadFile(path)
+			h.Write([]byte(path))
+			h.Write(schema)
+		}
+	}
+
+	var hash [sha256.Size]byte
+	h.Sum(hash[:0])
+	return hash
+}
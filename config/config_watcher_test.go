@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	articleConfig = `content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "generic"
+    end-point: "http://upp-article-validator:8080"
+`
+	liveBlogConfig = articleConfig + `  "application/vnd.ft-upp-live-blog-post+json":
+    validator: "generic"
+    end-point: "http://upp-live-blog-post-validator:8080"
+`
+)
+
+func writeConfig(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherAppliesChangedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeConfig(t, path, articleConfig)
+	current, err := ReadConfig(path)
+	assert.NoError(t, err)
+
+	var applied []*Config
+	w := NewWatcher(path, current, 0, func(cfg *Config) error {
+		applied = append(applied, cfg)
+		return nil
+	}, logger.NewUPPLogger("Test", "PANIC"))
+
+	assert.NoError(t, w.check(false))
+	assert.Empty(t, applied, "an unchanged file should not be applied")
+
+	writeConfig(t, path, liveBlogConfig)
+	assert.NoError(t, w.check(false))
+	if assert.Len(t, applied, 1) {
+		assert.Contains(t, applied[0].ContentTypes, "application/vnd.ft-upp-live-blog-post+json")
+	}
+	assert.Same(t, applied[0], w.Current())
+}
+
+func TestWatcherKeepsLastGoodConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeConfig(t, path, articleConfig)
+	current, err := ReadConfig(path)
+	assert.NoError(t, err)
+
+	calls := 0
+	w := NewWatcher(path, current, 0, func(cfg *Config) error {
+		calls++
+		return errors.New("unable to find service with endpoint http://upp-live-blog-post-validator:8080")
+	}, logger.NewUPPLogger("Test", "PANIC"))
+
+	writeConfig(t, path, "content-types: [")
+	assert.Error(t, w.check(false))
+	assert.Equal(t, 0, calls, "a config which cannot be parsed should not be applied")
+
+	writeConfig(t, path, liveBlogConfig)
+	assert.Error(t, w.check(false))
+	assert.NoError(t, w.check(false), "a rejected config should not be retried until it changes")
+	assert.Equal(t, 1, calls)
+	assert.Same(t, current, w.Current())
+
+	assert.Error(t, w.Reload(), "an explicit reload should retry the rejected config")
+	assert.Equal(t, 2, calls)
+}
+
+func TestWatcherResolvesRelativeSchemaPaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "schemas"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(dir, "schemas", "article.json"), `{"type": "object"}`)
+	schemaConfig := `content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "jsonschema"
+    schema: "schemas/article.json"
+`
+	path := filepath.Join(dir, "config.yml")
+	writeConfig(t, path, schemaConfig)
+	current, err := ReadConfig(path)
+	assert.NoError(t, err)
+
+	var applied []*Config
+	w := NewWatcher(path, current, 0, func(cfg *Config) error {
+		applied = append(applied, cfg)
+		return nil
+	}, logger.NewUPPLogger("Test", "PANIC"))
+
+	assert.NoError(t, w.check(false), "the schema is looked up relative to the configuration file")
+	assert.Empty(t, applied, "an unchanged file should not be applied")
+
+	writeConfig(t, path, schemaConfig+`  "application/vnd.ft-upp-live-blog-post+json":
+    validator: "jsonschema"
+    schema: "schemas/article.json"
+`)
+	assert.NoError(t, w.check(false))
+	if assert.Len(t, applied, 1) {
+		assert.Equal(t, filepath.Join(dir, "schemas", "article.json"), applied[0].ContentTypes["application/vnd.ft-upp-live-blog-post+json"].Schema)
+	}
+}
+
+func TestWatcherAppliesChangedSchemas(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "article.json")
+	writeConfig(t, schemaPath, `{"type": "object"}`)
+	path := filepath.Join(dir, "config.yml")
+	writeConfig(t, path, `content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "jsonschema"
+    schema: "article.json"
+`)
+	current, err := ReadConfig(path)
+	assert.NoError(t, err)
+
+	applied := 0
+	w := NewWatcher(path, current, 0, func(cfg *Config) error {
+		applied++
+		return nil
+	}, logger.NewUPPLogger("Test", "PANIC"))
+
+	writeConfig(t, schemaPath, `{"type": "object", "required": ["uuid"]}`)
+	assert.NoError(t, w.check(false))
+	assert.Equal(t, 1, applied, "a changed schema should be applied although the configuration file is unchanged")
+
+	assert.NoError(t, w.check(false))
+	assert.Equal(t, 1, applied)
+
+	assert.NoError(t, w.Reload())
+	assert.Equal(t, 2, applied, "an explicit reload should always apply the configuration")
+}
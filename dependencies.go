@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	metrics "github.com/rcrowley/go-metrics"
+
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
+	"github.com/Financial-Times/draft-content-suggestions/monitoring"
+	"github.com/Financial-Times/draft-content-suggestions/retry"
+	"github.com/Financial-Times/draft-content-suggestions/tracing"
+)
+
+// dependencyClients creates the HTTP client of every downstream dependency, each guarded by its own circuit breaker.
+// A client is created once per dependency and endpoint, so that reloading the validator configuration keeps the
+// circuit state and the retry budget of the validators it does not change, while a validator moved to another
+// endpoint starts afresh.
+// The calls are bounded by the timeout, unless they already have a deadline, like the ones of the suggestions jobs
+// which can take longer than a synchronous request.
+type dependencyClients struct {
+	base            *http.Client
+	timeout         time.Duration
+	breakerSettings breaker.Settings
+	metrics         *monitoring.Metrics
+	log             *logger.UPPLogger
+
+	mu      sync.Mutex
+	clients map[dependencyKey]dependencyClient
+}
+
+type dependencyKey struct {
+	dependency string
+	endpoint   string
+}
+
+type dependencyClient struct {
+	client  *http.Client
+	breaker *breaker.Breaker
+}
+
+func newDependencyClients(base *http.Client, timeout time.Duration, breakerSettings breaker.Settings, m *monitoring.Metrics, log *logger.UPPLogger) *dependencyClients {
+	return &dependencyClients{
+		base:            base,
+		timeout:         timeout,
+		breakerSettings: breakerSettings,
+		metrics:         m,
+		log:             log,
+		clients:         map[dependencyKey]dependencyClient{},
+	}
+}
+
+// client returns the client of the dependency at the endpoint. Its circuit breaker wraps the retries, so that an
+// open circuit fails fast and a call only counts as failed once all its attempts have failed. Every attempt
+// reaching the dependency is traced and recorded in its latency metrics.
+func (d *dependencyClients) client(dependency string, endpoint string, policy retry.Policy) *http.Client {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := dependencyKey{dependency: dependency, endpoint: endpoint}
+	if c, found := d.clients[key]; found {
+		return c.client
+	}
+
+	b := breaker.New(dependency, d.breakerSettings, metrics.DefaultRegistry)
+	instrumented := tracing.NewClient(d.metrics.NewClient(d.base, dependency), dependency)
+	client := breaker.NewClient(retry.NewClient(instrumented, dependency, policy, d.log), b)
+	client.Transport = &timeoutTransport{next: client.Transport, timeout: d.timeout}
+	d.clients[key] = dependencyClient{client: client, breaker: b}
+	return client
+}
+
+// breakersFor returns the circuit breakers of the given dependencies, mapped to their endpoints, skipping the ones
+// without a client.
+func (d *dependencyClients) breakersFor(endpoints map[string]string) []*breaker.Breaker {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var breakers []*breaker.Breaker
+	for dependency, endpoint := range endpoints {
+		if c, found := d.clients[dependencyKey{dependency: dependency, endpoint: endpoint}]; found {
+			breakers = append(breakers, c.breaker)
+		}
+	}
+	return breakers
+}
+
+// retain drops the clients of the dependencies missing from the given ones, mapped to their endpoints, such as the
+// validators removed or moved by a reload of the configuration.
+func (d *dependencyClients) retain(endpoints map[string]string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key := range d.clients {
+		if endpoint, found := endpoints[key.dependency]; !found || endpoint != key.endpoint {
+			delete(d.clients, key)
+		}
+	}
+}
+
+// timeoutTransport bounds the calls without a deadline to the timeout, across all their attempts.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, found := req.Context().Deadline(); found {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the body is still read once the call returns, so the timeout is only released when it is closed
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
+	"github.com/Financial-Times/draft-content-suggestions/monitoring"
+	"github.com/Financial-Times/draft-content-suggestions/retry"
+)
+
+func TestDependencyCallsWithoutDeadlineTimeOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	policy := retry.DefaultPolicy()
+	policy.MaxAttempts = 1
+	dependencies := newDependencyClients(&http.Client{}, 10*time.Millisecond, breaker.Settings{FailureThreshold: 10, OpenTimeout: time.Second},
+		monitoring.New(metrics.NewRegistry()), logger.NewUPPLogger("test", "PANIC"))
+	client := dependencies.client("slow", server.URL, policy)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, err := client.Do(req)
+	assert.True(t, isTimeout(err), "calls without a deadline are bounded by the timeout")
+
+	// the calls of the suggestions jobs carry the longer deadline of their job
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.Do(req.WithContext(ctx))
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestDependencyClientsAreKeyedByEndpoint(t *testing.T) {
+	dependencies := newDependencyClients(&http.Client{}, time.Second, breaker.Settings{FailureThreshold: 10, OpenTimeout: time.Second},
+		monitoring.New(metrics.NewRegistry()), logger.NewUPPLogger("test", "PANIC"))
+	policy := retry.DefaultPolicy()
+
+	article := dependencies.client("article", "http://article-validator:8080", policy)
+	assert.Same(t, article, dependencies.client("article", "http://article-validator:8080", policy))
+	moved := dependencies.client("article", "http://article-validator-v2:8080", policy)
+	assert.NotSame(t, article, moved, "a validator moved to another endpoint does not inherit the circuit state")
+	dependencies.client("live-blog", "http://live-blog-validator:8080", policy)
+
+	current := map[string]string{"article": "http://article-validator-v2:8080"}
+	assert.Len(t, dependencies.breakersFor(current), 1)
+
+	dependencies.retain(current)
+	assert.Len(t, dependencies.clients, 1, "the clients no longer referred to are dropped")
+	assert.Same(t, moved, dependencies.client("article", "http://article-validator-v2:8080", policy))
+}
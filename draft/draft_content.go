@@ -10,8 +10,10 @@ import (
 
 	"github.com/Financial-Times/draft-content-suggestions/config"
 	"github.com/Financial-Times/draft-content-suggestions/endpointessentials"
+	"github.com/Financial-Times/draft-content-suggestions/tracing"
 	"github.com/Financial-Times/go-logger/v2"
 	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -46,6 +48,8 @@ func NewContentAPI(endpoint string, healthEndpoint string, httpClient *http.Clie
 type ContentAPI interface {
 	FetchDraftContent(ctx context.Context, uuid string) (content []byte, err error)
 	FetchValidatedContent(ctx context.Context, body io.Reader, contentUUID string, contentType string, log *logger.UPPLogger) ([]byte, error)
+	// ContentTypes returns the content-types FetchValidatedContent has a validator for.
+	ContentTypes() []string
 	endpointessentials.Endpoint
 }
 
@@ -57,7 +61,10 @@ type draftContentAPI struct {
 	resolver         ContentValidatorResolver
 }
 
-func (d *draftContentAPI) FetchDraftContent(ctx context.Context, uuid string) ([]byte, error) {
+func (d *draftContentAPI) FetchDraftContent(ctx context.Context, uuid string) (content []byte, err error) {
+	ctx, span := tracing.Start(ctx, "draft.FetchDraftContent", attribute.String("ft.uuid", uuid))
+	defer func() { tracing.End(span, err) }()
+
 	requestPath := d.endpoint + uuid
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestPath, nil)
 	if err != nil {
@@ -88,7 +95,11 @@ func (d *draftContentAPI) FetchDraftContent(ctx context.Context, uuid string) ([
 	return bytes, nil
 }
 
-func (d *draftContentAPI) FetchValidatedContent(ctx context.Context, body io.Reader, contentUUID string, contentType string, log *logger.UPPLogger) ([]byte, error) {
+func (d *draftContentAPI) FetchValidatedContent(ctx context.Context, body io.Reader, contentUUID string, contentType string, log *logger.UPPLogger) (content []byte, err error) {
+	ctx, span := tracing.Start(ctx, "draft.FetchValidatedContent",
+		attribute.String("ft.uuid", contentUUID), attribute.String("ft.content_type", contentType))
+	defer func() { tracing.End(span, err) }()
+
 	tid, _ := tidutils.GetTransactionIDFromContext(ctx)
 	readLog := log.WithField(tidutils.TransactionIDHeader, tid).WithField("uuid", contentUUID)
 
@@ -101,18 +112,19 @@ func (d *draftContentAPI) FetchValidatedContent(ctx context.Context, body io.Rea
 		return nil, resolverErr
 	}
 
-	validatedContent, err := validator.Validate(ctx, contentUUID, body, contentType, log)
+	validatedContent, err = validator.Validate(ctx, contentUUID, body, contentType, log)
 	if err != nil {
 		readLog.WithError(err).Warn("Validator error")
+		// the validator error is wrapped rather than replaced, so that its details can still be reported
 		var validatorError ValidatorError
 		if errors.As(err, &validatorError) {
 			switch validatorError.StatusCode() {
 			case http.StatusNotFound:
 				fallthrough
 			case http.StatusUnsupportedMediaType:
-				err = ErrDraftContentTypeNotSupported
+				err = fmt.Errorf("%w: %w", ErrDraftContentTypeNotSupported, err)
 			case http.StatusUnprocessableEntity:
-				err = ErrDraftNotValid
+				err = fmt.Errorf("%w: %w", ErrDraftNotValid, err)
 			}
 		}
 		return nil, err
@@ -127,28 +139,98 @@ func (d *draftContentAPI) FetchValidatedContent(ctx context.Context, body io.Rea
 	return bytes, err
 }
 
-func BuildContentTypeMapping(validatorConfig *config.Config, httpClient *http.Client, log *logger.UPPLogger) map[string]ContentValidator {
+func (d *draftContentAPI) ContentTypes() []string {
+	return d.resolver.ContentTypes()
+}
+
+// HTTPClientProvider returns the HTTP client used to call a validator at its endpoint. The validator is named after
+// its content type, or after the stage of its chain by ChainStageDependency.
+type HTTPClientProvider func(dependency string, endpoint string) *http.Client
+
+// ChainStageDependency names the validator of a stage of the chain of the content type, from its index in the chain,
+// so that every stage calling a validator service gets its own client.
+func ChainStageDependency(contentType string, i int) string {
+	return fmt.Sprintf("%s/chain[%d]", contentType, i)
+}
+
+// ValidatorDependencies returns the endpoint of every validator service of the configuration, by the name its
+// client is created with.
+func ValidatorDependencies(cfg *config.Config) map[string]string {
+	dependencies := map[string]string{}
+	for contentType, validator := range cfg.ContentTypes {
+		if validator.Validator == config.ValidatorGeneric {
+			dependencies[contentType] = validator.Endpoint
+		}
+		for i, stage := range validator.Chain {
+			if stage.Validator == config.ValidatorGeneric {
+				dependencies[ChainStageDependency(contentType, i)] = stage.Endpoint
+			}
+		}
+	}
+	return dependencies
+}
+
+// SharedHTTPClient is a HTTPClientProvider using the same client for every validator.
+func SharedHTTPClient(httpClient *http.Client) HTTPClientProvider {
+	return func(string, string) *http.Client {
+		return httpClient
+	}
+}
+
+// BuildContentTypeMapping creates the validator configured for every content type.
+func BuildContentTypeMapping(validatorConfig *config.Config, httpClientFor HTTPClientProvider, log *logger.UPPLogger) (map[string]ContentValidator, error) {
 	contentTypeMapping := map[string]ContentValidator{}
 
 	for contentType, cfg := range validatorConfig.ContentTypes {
-		var service ContentValidator
-
-		switch cfg.Validator {
-		case "generic":
-			service = NewDraftContentValidatorService(cfg.Endpoint, httpClient)
-		default:
-			log.WithField("Validator", cfg.Validator).Fatal("Unknown validator")
+		service, err := buildValidator(contentType, contentType, cfg, httpClientFor)
+		if err != nil {
+			return nil, err
 		}
 		contentTypeMapping[contentType] = service
+		for _, alias := range cfg.Aliases {
+			contentTypeMapping[alias] = service
+		}
 
 		log.
 			WithField("Content-Type", contentType).
+			WithField("Aliases", cfg.Aliases).
 			WithField("Endpoint", cfg.Endpoint).
+			WithField("Schema", cfg.Schema).
 			WithField("Validator", cfg.Validator).
 			Info("added validator service")
 	}
 
-	return contentTypeMapping
+	return contentTypeMapping, nil
+}
+
+func buildValidator(contentType string, dependency string, cfg config.ValidatorConfig, httpClientFor HTTPClientProvider) (ContentValidator, error) {
+	switch cfg.Validator {
+	case config.ValidatorGeneric:
+		return NewDraftContentValidatorService(cfg.Endpoint, httpClientFor(dependency, cfg.Endpoint)), nil
+	case config.ValidatorJSONSchema:
+		service, err := NewJSONSchemaValidator(cfg.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator for content-type %s: %w", contentType, err)
+		}
+		return service, nil
+	case config.ValidatorPassthrough:
+		return NewPassthroughValidator(), nil
+	case config.ValidatorChained:
+		stages := make([]ChainStage, 0, len(cfg.Chain))
+		for i, stageCfg := range cfg.Chain {
+			if stageCfg.Validator == config.ValidatorChained {
+				return nil, fmt.Errorf("chained validator for content-type %s cannot be nested", contentType)
+			}
+			stage, err := buildValidator(contentType, ChainStageDependency(contentType, i), stageCfg, httpClientFor)
+			if err != nil {
+				return nil, err
+			}
+			stages = append(stages, ChainStage{Name: fmt.Sprintf("%d (%s)", i+1, stageCfg.Validator), Validator: stage})
+		}
+		return NewChainedValidator(stages), nil
+	default:
+		return nil, fmt.Errorf("unknown validator %q for content-type %s", cfg.Validator, contentType)
+	}
 }
 
 func (d *draftContentAPI) Endpoint() string {
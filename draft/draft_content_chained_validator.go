@@ -0,0 +1,91 @@
+package draft
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Financial-Times/go-logger/v2"
+)
+
+// ChainStage is a validator of a chain, along with the name its failures are reported with.
+type ChainStage struct {
+	Name      string
+	Validator ContentValidator
+}
+
+// chainedValidator pipes the content through the validators of its chain, e.g. a cheap local schema check
+// before the remote UPP validator, the output of a stage being the input of the next one.
+type chainedValidator struct {
+	stages []ChainStage
+}
+
+// NewChainedValidator returns a validator running the stages in order, and stopping at the first failing one.
+func NewChainedValidator(stages []ChainStage) ContentValidator {
+	return &chainedValidator{stages: stages}
+}
+
+func (v *chainedValidator) Validate(ctx context.Context, contentUUID string, nativeBody io.Reader, contentType string, log *logger.UPPLogger) (io.ReadCloser, error) {
+	var output io.ReadCloser = io.NopCloser(nativeBody)
+	input := nativeBody
+
+	for i, stage := range v.stages {
+		var err error
+		output, err = stage.Validator.Validate(ctx, contentUUID, input, contentType, log)
+		if err != nil {
+			var validatorErr ValidatorError
+			if errors.As(err, &validatorErr) {
+				validatorErr.stage = stage.Name
+				validatorErr.msg = fmt.Sprintf("validator stage %s has failed: %s", stage.Name, validatorErr.msg)
+				return nil, validatorErr
+			}
+			return nil, fmt.Errorf("validator stage %s has failed: %w", stage.Name, err)
+		}
+		// the next stage is given the content with the media type the stage has output it as
+		contentType = ContentTypeOf(output, contentType)
+		if i == len(v.stages)-1 {
+			break
+		}
+
+		// the output is buffered, so that the next stage can send it again when its call is retried
+		buffered, err := io.ReadAll(output)
+		output.Close()
+		if err != nil {
+			return nil, fmt.Errorf("validator stage %s has failed: %w", stage.Name, err)
+		}
+		input = bytes.NewReader(buffered)
+	}
+
+	return output, nil
+}
+
+// GTG is good to go when every stage is.
+func (v *chainedValidator) GTG() error {
+	for _, stage := range v.stages {
+		if err := stage.Validator.GTG(); err != nil {
+			return fmt.Errorf("validator stage %s is not good to go: %w", stage.Name, err)
+		}
+	}
+	return nil
+}
+
+// Endpoint is empty, as the stages have their own.
+func (v *chainedValidator) Endpoint() string {
+	return ""
+}
+
+// Stages returns the validators the given one is made of: the stages of a chained validator, or the validator itself.
+func Stages(validator ContentValidator) []ContentValidator {
+	chained, ok := validator.(*chainedValidator)
+	if !ok {
+		return []ContentValidator{validator}
+	}
+
+	stages := make([]ContentValidator, 0, len(chained.stages))
+	for _, stage := range chained.stages {
+		stages = append(stages, stage.Validator)
+	}
+	return stages
+}
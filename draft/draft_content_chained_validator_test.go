@@ -0,0 +1,149 @@
+package draft
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Financial-Times/draft-content-suggestions/retry"
+)
+
+// upperCaseValidator stands for a normaliser stage, whose output differs from its input.
+type upperCaseValidator struct{}
+
+func (upperCaseValidator) Validate(_ context.Context, _ string, nativeBody io.Reader, _ string, _ *logger.UPPLogger) (io.ReadCloser, error) {
+	body, err := io.ReadAll(nativeBody)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(bytes.ToUpper(body))), nil
+}
+
+func (upperCaseValidator) GTG() error {
+	return nil
+}
+
+func (upperCaseValidator) Endpoint() string {
+	return ""
+}
+
+func TestChainedValidator(t *testing.T) {
+	v := NewChainedValidator([]ChainStage{
+		{Name: "1 (passthrough)", Validator: NewPassthroughValidator()},
+		{Name: "2 (normaliser)", Validator: upperCaseValidator{}},
+	})
+
+	body, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db",
+		strings.NewReader(`{"uuid":"36320eb6-5617-4d12-9750-1907690e74db"}`), contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+	assert.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"UUID":"36320EB6-5617-4D12-9750-1907690E74DB"}`, string(content), "the output of a stage is the input of the next one")
+}
+
+func TestChainedValidatorPassesOnTheOutputMediaTypeAndRetriesLaterStages(t *testing.T) {
+	mapper := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, contentTypeArticle, r.Header.Get("Content-Type"))
+		w.Header().Set("Content-Type", "application/vnd.ft-upp-content+json")
+		_, _ = w.Write([]byte(`{"mapped":true}`))
+	}))
+	defer mapper.Close()
+	attempts := 0
+	checker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "application/vnd.ft-upp-content+json", r.Header.Get("Content-Type"), "the media type output by the previous stage")
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"mapped":true}`, string(body))
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer checker.Close()
+
+	log := logger.NewUPPLogger("test logger", "PANIC")
+	policy := retry.DefaultPolicy().WithIdempotentMethods(http.MethodPost)
+	policy.BaseDelay = time.Millisecond
+	v := NewChainedValidator([]ChainStage{
+		{Name: "1 (mapper)", Validator: NewDraftContentValidatorService(mapper.URL, http.DefaultClient)},
+		{Name: "2 (checker)", Validator: NewDraftContentValidatorService(checker.URL, retry.NewClient(http.DefaultClient, "checker", policy, log))},
+	})
+
+	body, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db", strings.NewReader(`{}`), contentTypeArticle, log)
+
+	if assert.NoError(t, err) {
+		defer body.Close()
+		content, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"mapped":true}`, string(content))
+	}
+	assert.Equal(t, 2, attempts, "the input of a later stage can be sent again")
+}
+
+func TestChainedValidatorStopsAtFirstFailingStage(t *testing.T) {
+	log := logger.NewUPPLogger("test logger", "PANIC")
+	notReached := &MockValidator{}
+	v := NewChainedValidator([]ChainStage{
+		{Name: "1 (normaliser)", Validator: upperCaseValidator{}},
+		{Name: "2 (passthrough)", Validator: NewPassthroughValidator()},
+		{Name: "3 (generic)", Validator: notReached},
+	})
+
+	body, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db",
+		strings.NewReader(`{"uuid":"36320eb6-5617-4d12-9750-1907690e74db"}`), contentTypeArticle, log)
+
+	assert.Nil(t, body)
+	var validatorErr ValidatorError
+	if assert.ErrorAs(t, err, &validatorErr) {
+		assert.Equal(t, http.StatusBadRequest, validatorErr.StatusCode())
+		assert.Equal(t, "2 (passthrough)", validatorErr.Stage())
+		assert.Contains(t, validatorErr.Error(), "validator stage 2 (passthrough) has failed")
+	}
+	notReached.AssertNotCalled(t, "Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChainedValidatorKeepsOtherErrors(t *testing.T) {
+	failing := &MockValidator{}
+	failing.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(bytes.NewReader(nil), context.DeadlineExceeded)
+	v := NewChainedValidator([]ChainStage{{Name: "1 (generic)", Validator: failing}})
+
+	_, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db", strings.NewReader(`{}`), contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "validator stage 1 (generic) has failed")
+}
+
+func TestChainedValidatorGTG(t *testing.T) {
+	unavailable := &MockValidator{}
+	unavailable.On("GTG").Return(errors.New("connection refused"))
+
+	assert.NoError(t, NewChainedValidator([]ChainStage{{Name: "1 (passthrough)", Validator: NewPassthroughValidator()}}).GTG())
+	assert.ErrorContains(t, NewChainedValidator([]ChainStage{
+		{Name: "1 (passthrough)", Validator: NewPassthroughValidator()},
+		{Name: "2 (generic)", Validator: unavailable},
+	}).GTG(), "validator stage 2 (generic) is not good to go")
+}
+
+func TestStages(t *testing.T) {
+	remote := NewDraftContentValidatorService("http://upp-article-validator:8080", http.DefaultClient)
+	passthrough := NewPassthroughValidator()
+
+	assert.Equal(t, []ContentValidator{remote}, Stages(remote))
+	assert.Equal(t, []ContentValidator{passthrough, remote}, Stages(NewChainedValidator([]ChainStage{
+		{Name: "1 (passthrough)", Validator: passthrough},
+		{Name: "2 (generic)", Validator: remote},
+	})))
+}
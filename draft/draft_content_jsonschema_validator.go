@@ -0,0 +1,97 @@
+package draft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// jsonSchemaValidator validates the native body against a JSON Schema, without any network hop,
+// for the content types whose mapping is an identity transform.
+type jsonSchemaValidator struct {
+	schemaPath string
+	schema     *jsonschema.Schema
+}
+
+// NewJSONSchemaValidator compiles the JSON Schema file, so that a broken schema is reported before any content is validated.
+func NewJSONSchemaValidator(schemaPath string) (ContentValidator, error) {
+	schema, err := jsonschema.Compile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile JSON schema %s: %w", schemaPath, err)
+	}
+	return &jsonSchemaValidator{schemaPath: schemaPath, schema: schema}, nil
+}
+
+func (v *jsonSchemaValidator) Validate(_ context.Context, contentUUID string, nativeBody io.Reader, contentType string, log *logger.UPPLogger) (io.ReadCloser, error) {
+	body, err := io.ReadAll(nativeBody)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, localValidatorError(contentUUID, contentType, fmt.Sprintf("invalid JSON: %v", err), nil)
+	}
+
+	err = v.schema.Validate(doc)
+	if err != nil {
+		schemaErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return nil, err
+		}
+		log.WithField("uuid", contentUUID).WithField("schema", v.schemaPath).Debug("Content has failed JSON schema validation")
+		return nil, localValidatorError(contentUUID, contentType, schemaErr.Message, leafFieldErrors(schemaErr))
+	}
+
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+// GTG always succeeds, as there is no service behind the validator.
+func (v *jsonSchemaValidator) GTG() error {
+	return nil
+}
+
+// Endpoint is empty, as there is no service behind the validator.
+func (v *jsonSchemaValidator) Endpoint() string {
+	return ""
+}
+
+// localValidatorError builds the same error a remote validator reports when the validation has failed.
+func localValidatorError(contentUUID string, contentType string, reason string, fieldErrors []FieldError) ValidatorError {
+	payload, _ := json.Marshal(struct {
+		Error  string       `json:"error"`
+		Errors []FieldError `json:"errors,omitempty"`
+	}{reason, fieldErrors})
+	return ValidatorError{
+		httpStatus: http.StatusBadRequest,
+		msg: fmt.Sprintf(
+			"Content with uuid: %s, content-type: %s has failed validation/mapping with reason: %v",
+			contentUUID,
+			contentType,
+			reason,
+		),
+		payload:     payload,
+		fieldErrors: fieldErrors,
+	}
+}
+
+// leafFieldErrors flattens the validation error tree into the errors of the fields actually at fault.
+func leafFieldErrors(err *jsonschema.ValidationError) []FieldError {
+	if len(err.Causes) == 0 {
+		return []FieldError{{Field: err.InstanceLocation, Message: err.Message}}
+	}
+
+	var fieldErrors []FieldError
+	for _, cause := range err.Causes {
+		fieldErrors = append(fieldErrors, leafFieldErrors(cause)...)
+	}
+	return fieldErrors
+}
@@ -0,0 +1,88 @@
+package draft
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["uuid", "title"],
+	"properties": {
+		"uuid": {"type": "string"},
+		"title": {"type": "string"},
+		"byline": {"type": "string"}
+	}
+}`
+
+func writeTestSchema(t *testing.T, schema string) string {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJSONSchemaValidator(t *testing.T) {
+	v, err := NewJSONSchemaValidator(writeTestSchema(t, testSchema))
+	assert.NoError(t, err)
+
+	nativeBody := `{"uuid": "36320eb6-5617-4d12-9750-1907690e74db", "title": "Invesco launches first green building ETF"}`
+	body, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db", strings.NewReader(nativeBody),
+		contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+	assert.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, nativeBody, string(content), "the content is returned unchanged")
+	assert.NoError(t, v.GTG())
+}
+
+func TestJSONSchemaValidatorInvalidContent(t *testing.T) {
+	v, err := NewJSONSchemaValidator(writeTestSchema(t, testSchema))
+	assert.NoError(t, err)
+
+	body, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db",
+		strings.NewReader(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db", "byline": 42}`),
+		contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+	assert.Nil(t, body)
+	var validatorErr ValidatorError
+	if assert.ErrorAs(t, err, &validatorErr) {
+		assert.Equal(t, http.StatusBadRequest, validatorErr.StatusCode())
+		assert.Len(t, validatorErr.FieldErrors(), 2)
+		assert.Contains(t, validatorErr.FieldErrors(), FieldError{Field: "/byline", Message: "expected string, but got number"})
+		assert.Contains(t, string(validatorErr.Payload()), `"errors":[`)
+	}
+}
+
+func TestJSONSchemaValidatorMalformedContent(t *testing.T) {
+	v, err := NewJSONSchemaValidator(writeTestSchema(t, testSchema))
+	assert.NoError(t, err)
+
+	_, err = v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db", strings.NewReader(`{"uuid": `),
+		contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+	var validatorErr ValidatorError
+	if assert.ErrorAs(t, err, &validatorErr) {
+		assert.Equal(t, http.StatusBadRequest, validatorErr.StatusCode())
+		assert.Contains(t, validatorErr.Error(), "invalid JSON")
+	}
+}
+
+func TestNewJSONSchemaValidatorInvalidSchema(t *testing.T) {
+	_, err := NewJSONSchemaValidator(writeTestSchema(t, `{"type": 42}`))
+	assert.Error(t, err)
+
+	_, err = NewJSONSchemaValidator(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package draft
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassthroughValidator(t *testing.T) {
+	v := NewPassthroughValidator()
+	nativeBody := `{"uuid": "36320eb6-5617-4d12-9750-1907690e74db", "title": "Invesco launches first green building ETF"}`
+
+	body, err := v.Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db", strings.NewReader(nativeBody),
+		contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+	assert.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, nativeBody, string(content), "the content is returned unchanged")
+	assert.NoError(t, v.GTG())
+	assert.Empty(t, v.Endpoint())
+}
+
+func TestPassthroughValidatorInvalidContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		nativeBody string
+		field      string
+	}{
+		{name: "malformed JSON", nativeBody: `{"uuid": `},
+		{name: "not an object", nativeBody: `["36320eb6-5617-4d12-9750-1907690e74db"]`},
+		{name: "missing uuid", nativeBody: `{"title": "Invesco launches first green building ETF"}`, field: "uuid"},
+		{name: "other uuid", nativeBody: `{"uuid": "711e5bc1-3470-4297-ae26-154f145a6287"}`, field: "uuid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := NewPassthroughValidator().Validate(context.Background(), "36320eb6-5617-4d12-9750-1907690e74db",
+				strings.NewReader(test.nativeBody), contentTypeArticle, logger.NewUPPLogger("test logger", "PANIC"))
+
+			assert.Nil(t, body)
+			var validatorErr ValidatorError
+			if assert.ErrorAs(t, err, &validatorErr) {
+				assert.Equal(t, http.StatusBadRequest, validatorErr.StatusCode())
+				if test.field != "" && assert.Len(t, validatorErr.FieldErrors(), 1) {
+					assert.Equal(t, test.field, validatorErr.FieldErrors()[0].Field)
+				}
+			}
+		})
+	}
+}
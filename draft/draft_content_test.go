@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/Financial-Times/draft-content-suggestions/config"
 	"github.com/Financial-Times/draft-content-suggestions/mocks"
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/stretchr/testify/assert"
@@ -120,7 +121,6 @@ func TestDraftContentAPI_FetchValidatedContent(t *testing.T) {
 			retMockResolverValidator: &MockValidator{},
 			retMockValidatorBody:     bytes.NewReader([]byte{}),
 			retMockValidatorErr:      ValidatorError{httpStatus: http.StatusBadRequest, msg: "Validator error"},
-			expectedValidatorErr:     ValidatorError{httpStatus: http.StatusBadRequest, msg: "Validator error"},
 		},
 		{
 			name:                     "Unsuccessful fetch caused by validator error with unsupported media type",
@@ -159,7 +159,12 @@ func TestDraftContentAPI_FetchValidatedContent(t *testing.T) {
 				assert.Equal(t, test.retMockResolverErr, err)
 			}
 			if test.retMockValidatorErr != nil {
-				assert.Equal(t, test.expectedValidatorErr, err)
+				if test.expectedValidatorErr != nil {
+					assert.ErrorIs(t, err, test.expectedValidatorErr)
+				}
+				var validatorErr ValidatorError
+				assert.ErrorAs(t, err, &validatorErr, "the validator error is kept")
+				assert.Equal(t, test.retMockValidatorErr, validatorErr)
 			}
 			assert.Equal(t, test.expectedContentResult, content)
 		})
@@ -221,3 +226,108 @@ func TestDraftContentAPI_FetchDraftContentNon200(t *testing.T) {
 	assert.EqualError(t, err, "error in draft content retrival status=500")
 	assert.True(t, content == nil)
 }
+
+func TestBuildContentTypeMapping(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	cfg := &config.Config{ContentTypes: map[string]config.ValidatorConfig{
+		contentTypeArticle: {Validator: "generic", Endpoint: "http://upp-article-validator:8080"},
+	}}
+
+	mapping, err := BuildContentTypeMapping(cfg, SharedHTTPClient(http.DefaultClient), log)
+
+	assert.NoError(t, err)
+	if assert.Contains(t, mapping, contentTypeArticle) {
+		assert.Equal(t, "http://upp-article-validator:8080", mapping[contentTypeArticle].Endpoint())
+	}
+}
+
+func TestBuildContentTypeMappingJSONSchemaValidator(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	cfg := &config.Config{ContentTypes: map[string]config.ValidatorConfig{
+		contentTypeArticle: {Validator: "jsonschema", Schema: writeTestSchema(t, testSchema)},
+	}}
+	noClient := func(string, string) *http.Client {
+		t.Fatal("the jsonschema validator should not need an HTTP client")
+		return nil
+	}
+
+	mapping, err := BuildContentTypeMapping(cfg, noClient, log)
+
+	assert.NoError(t, err)
+	assert.Contains(t, mapping, contentTypeArticle)
+}
+
+func TestBuildContentTypeMappingPassthroughValidator(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	cfg := &config.Config{ContentTypes: map[string]config.ValidatorConfig{
+		contentTypeArticle: {Validator: "passthrough"},
+	}}
+
+	mapping, err := BuildContentTypeMapping(cfg, SharedHTTPClient(http.DefaultClient), log)
+
+	assert.NoError(t, err)
+	if assert.Contains(t, mapping, contentTypeArticle) {
+		assert.NoError(t, mapping[contentTypeArticle].GTG())
+	}
+}
+
+func TestBuildContentTypeMappingChainedValidator(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	cfg := &config.Config{ContentTypes: map[string]config.ValidatorConfig{
+		contentTypeArticle: {Validator: "chained", Chain: []config.ValidatorConfig{
+			{Validator: "jsonschema", Schema: writeTestSchema(t, testSchema)},
+			{Validator: "generic", Endpoint: "http://upp-article-validator:8080"},
+		}},
+	}}
+
+	dependencies := map[string]string{}
+	clientFor := func(dependency string, endpoint string) *http.Client {
+		dependencies[dependency] = endpoint
+		return http.DefaultClient
+	}
+	mapping, err := BuildContentTypeMapping(cfg, clientFor, log)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"application/vnd.ft-upp-article+json/chain[1]": "http://upp-article-validator:8080"}, dependencies, "every stage gets its own client")
+	assert.Equal(t, dependencies, ValidatorDependencies(cfg))
+	if assert.Contains(t, mapping, contentTypeArticle) {
+		stages := Stages(mapping[contentTypeArticle])
+		if assert.Len(t, stages, 2) {
+			assert.Equal(t, "http://upp-article-validator:8080", stages[1].Endpoint())
+		}
+	}
+
+	cfg.ContentTypes[contentTypeArticle] = config.ValidatorConfig{Validator: "chained", Chain: []config.ValidatorConfig{{Validator: "chained"}}}
+	_, err = BuildContentTypeMapping(cfg, SharedHTTPClient(http.DefaultClient), log)
+	assert.EqualError(t, err, "chained validator for content-type application/vnd.ft-upp-article+json cannot be nested")
+}
+
+func TestBuildContentTypeMappingAliases(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	cfg := &config.Config{ContentTypes: map[string]config.ValidatorConfig{
+		contentTypeArticle: {
+			Aliases:   []string{"application/vnd.ft-upp-article-internal+json", "application/json; profile=article"},
+			Validator: "generic",
+			Endpoint:  "http://upp-article-validator:8080",
+		},
+	}}
+
+	mapping, err := BuildContentTypeMapping(cfg, SharedHTTPClient(http.DefaultClient), log)
+
+	assert.NoError(t, err)
+	assert.Len(t, mapping, 3)
+	assert.Same(t, mapping[contentTypeArticle], mapping["application/vnd.ft-upp-article-internal+json"])
+	assert.Same(t, mapping[contentTypeArticle], mapping["application/json; profile=article"])
+}
+
+func TestBuildContentTypeMappingUnknownValidator(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	cfg := &config.Config{ContentTypes: map[string]config.ValidatorConfig{
+		contentTypeArticle: {Validator: "genric", Endpoint: "http://upp-article-validator:8080"},
+	}}
+
+	mapping, err := BuildContentTypeMapping(cfg, SharedHTTPClient(http.DefaultClient), log)
+
+	assert.EqualError(t, err, `unknown validator "genric" for content-type application/vnd.ft-upp-article+json`)
+	assert.Nil(t, mapping)
+}
@@ -19,8 +19,11 @@ type ContentValidator interface {
 }
 
 type ValidatorError struct {
-	httpStatus int
-	msg        string
+	httpStatus  int
+	msg         string
+	payload     json.RawMessage
+	fieldErrors []FieldError
+	stage       string
 }
 
 func (e ValidatorError) Error() string {
@@ -31,6 +34,81 @@ func (e ValidatorError) StatusCode() int {
 	return e.httpStatus
 }
 
+// Payload returns the JSON error response of the validator, if it sent a valid one.
+func (e ValidatorError) Payload() json.RawMessage {
+	return e.payload
+}
+
+// Stage returns the name of the failing stage, when the validator is a chained one.
+func (e ValidatorError) Stage() string {
+	return e.stage
+}
+
+// FieldErrors returns the problems the validator found with specific fields of the content, if it listed any.
+func (e ValidatorError) FieldErrors() []FieldError {
+	return e.fieldErrors
+}
+
+// FieldError is a problem found by a validator with a field of the content, e.g. a JSON schema violation.
+type FieldError struct {
+	// Field is the path to the field in the content, in the notation of the validator.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// UnmarshalJSON accepts the shapes the validators list their errors in:
+// plain messages, or objects naming the field as field, path or instancePath.
+func (e *FieldError) UnmarshalJSON(data []byte) error {
+	var msg string
+	if err := json.Unmarshal(data, &msg); err == nil {
+		*e = FieldError{Message: msg}
+		return nil
+	}
+
+	var raw struct {
+		Field        string `json:"field"`
+		Path         string `json:"path"`
+		InstancePath string `json:"instancePath"`
+		Message      string `json:"message"`
+		Error        string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*e = FieldError{Message: raw.Message}
+	for _, field := range []string{raw.Field, raw.Path, raw.InstancePath} {
+		if field != "" {
+			e.Field = field
+			break
+		}
+	}
+	if e.Message == "" {
+		e.Message = raw.Error
+	}
+	return nil
+}
+
+// validatorErrorResponse is the part of the error response of a validator which lists field errors.
+type validatorErrorResponse struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// typedContent is validated content whose media type is known, such as the response of a UPP validator.
+type typedContent struct {
+	io.ReadCloser
+	contentType string
+}
+
+// ContentTypeOf returns the media type of content returned by a validator, or the given media type of the content
+// the validator was given when it did not tell it.
+func ContentTypeOf(validated io.Reader, contentType string) string {
+	if typed, ok := validated.(typedContent); ok {
+		return typed.contentType
+	}
+	return contentType
+}
+
 type draftContentValidator struct {
 	service *platform.Service
 }
@@ -65,6 +143,10 @@ func (validator *draftContentValidator) Validate(
 
 	switch resp.StatusCode {
 	case http.StatusOK:
+		// the validator maps the draft, so the validated content can have another media type than the draft
+		if mediaType := resp.Header.Get("Content-Type"); mediaType != "" {
+			return typedContent{ReadCloser: resp.Body, contentType: mediaType}, nil
+		}
 		return resp.Body, err
 	case http.StatusUnprocessableEntity: // content body validation/mapping has failed
 		fallthrough
@@ -75,8 +157,8 @@ func (validator *draftContentValidator) Validate(
 		responseBytes, err := io.ReadAll(resp.Body)
 
 		if err != nil {
-			return nil, ValidatorError{resp.StatusCode,
-				fmt.Sprintf(
+			return nil, ValidatorError{httpStatus: resp.StatusCode,
+				msg: fmt.Sprintf(
 					"Validation has failed for uuid: %s but couldn't consume response body, error: %v",
 					contentUUID,
 					err,
@@ -88,8 +170,8 @@ func (validator *draftContentValidator) Validate(
 		err = json.Unmarshal(responseBytes, &responseBody)
 
 		if err != nil {
-			return nil, ValidatorError{resp.StatusCode,
-				fmt.Sprintf(
+			return nil, ValidatorError{httpStatus: resp.StatusCode,
+				msg: fmt.Sprintf(
 					"Validation has failed for uuid: %s but couldn't unmarshal response body, error: %v",
 					contentUUID,
 					err,
@@ -104,12 +186,21 @@ func (validator *draftContentValidator) Validate(
 			responseBody["error"],
 		)
 
-		return nil, ValidatorError{resp.StatusCode, errorMessage}
+		// the field errors are a best effort, the payload is kept whatever its shape anyway
+		var details validatorErrorResponse
+		_ = json.Unmarshal(responseBytes, &details)
+
+		return nil, ValidatorError{
+			httpStatus:  resp.StatusCode,
+			msg:         errorMessage,
+			payload:     responseBytes,
+			fieldErrors: details.Errors,
+		}
 
 	default:
 		resp.Body.Close()
-		return nil, ValidatorError{resp.StatusCode,
-			fmt.Sprintf(
+		return nil, ValidatorError{httpStatus: resp.StatusCode,
+			msg: fmt.Sprintf(
 				"UPP Validator returned an unexpected HTTP status code in write operation: %v",
 				resp.StatusCode,
 			),
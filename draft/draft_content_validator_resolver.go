@@ -2,38 +2,126 @@ package draft
 
 import (
 	"fmt"
+	"mime"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // ContentValidatorResolver manages the validators available for a given originId/content-type pair.
 type ContentValidatorResolver interface {
 	// ValidatorForContentType Resolves and returns a ContentValidator implementation if present.
 	ValidatorForContentType(contentType string) (ContentValidator, error)
+	// ContentTypes returns the content-types a validator is configured for, sorted.
+	ContentTypes() []string
 }
 
-// NewContentValidatorResolver returns a ContentValidatorResolver implementation
-func NewContentValidatorResolver(contentTypeToValidator map[string]ContentValidator) ContentValidatorResolver {
-	return &contentValidatorResolver{contentTypeToValidator}
+// ReloadableContentValidatorResolver is a ContentValidatorResolver whose validators can be replaced at runtime.
+type ReloadableContentValidatorResolver interface {
+	ContentValidatorResolver
+	// Reload atomically replaces the validators resolved for every content-type.
+	Reload(contentTypeToValidator map[string]ContentValidator)
+}
+
+// NewContentValidatorResolver returns a ContentValidatorResolver implementation.
+// The content-types of the mapping can carry media-type parameters, e.g. application/json; profile=article,
+// which then have to be part of the requests resolved to their validator.
+func NewContentValidatorResolver(contentTypeToValidator map[string]ContentValidator) ReloadableContentValidatorResolver {
+	resolver := &contentValidatorResolver{}
+	resolver.Reload(contentTypeToValidator)
+	return resolver
 }
 
 type contentValidatorResolver struct {
+	mu                     sync.RWMutex
 	contentTypeToValidator map[string]ContentValidator
+	// routes are sorted from the most to the least specific one
+	routes []route
+}
+
+// route resolves the requests of a media type carrying at least the given parameters to a validator.
+type route struct {
+	contentType string
+	mediaType   string
+	params      map[string]string
+	validator   ContentValidator
 }
 
-// ValidatorForContentType implementation checks the content-type validation for a validator resolution.
+func (r route) matches(mediaType string, params map[string]string) bool {
+	if r.mediaType != mediaType {
+		return false
+	}
+	for name, value := range r.params {
+		if !strings.EqualFold(params[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reload implementation swaps the whole content-type mapping, so that a resolution never sees a partial update.
+func (resolver *contentValidatorResolver) Reload(contentTypeToValidator map[string]ContentValidator) {
+	routes := make([]route, 0, len(contentTypeToValidator))
+	for contentType, validator := range contentTypeToValidator {
+		mediaType, params := parseMediaType(contentType)
+		routes = append(routes, route{contentType: contentType, mediaType: mediaType, params: params, validator: validator})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if len(routes[i].params) != len(routes[j].params) {
+			return len(routes[i].params) > len(routes[j].params)
+		}
+		return routes[i].contentType < routes[j].contentType
+	})
+
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+
+	resolver.contentTypeToValidator = contentTypeToValidator
+	resolver.routes = routes
+}
+
+// ValidatorForContentType implementation resolves the most specific content-type matching the media type of the request
+// and its parameters, the parameters not configured for the content-type being ignored.
 func (resolver *contentValidatorResolver) ValidatorForContentType(contentType string) (ContentValidator, error) {
-	contentType = stripMediaTypeParameters(contentType)
-	validator, found := resolver.contentTypeToValidator[contentType]
-
-	if !found {
-		return nil, fmt.Errorf(
-			"no validator configured for contentType: %s\ncontentTypeMap: %v",
-			contentType,
-			resolver.contentTypeToValidator,
-		)
+	mediaType, params := parseMediaType(contentType)
+
+	resolver.mu.RLock()
+	defer resolver.mu.RUnlock()
+	for _, r := range resolver.routes {
+		if r.matches(mediaType, params) {
+			return r.validator, nil
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"%w: no validator configured for contentType: %s",
+		ErrDraftContentTypeNotSupported,
+		stripMediaTypeParameters(contentType),
+	)
+}
+
+// ContentTypes implementation lists the content-types of the current mapping.
+func (resolver *contentValidatorResolver) ContentTypes() []string {
+	resolver.mu.RLock()
+	defer resolver.mu.RUnlock()
+
+	contentTypes := make([]string, 0, len(resolver.contentTypeToValidator))
+	for contentType := range resolver.contentTypeToValidator {
+		contentTypes = append(contentTypes, contentType)
 	}
+	sort.Strings(contentTypes)
+	return contentTypes
+}
 
-	return validator, nil
+// parseMediaType returns the lower-cased media type and parameter names, the charset being ignored as it is no
+// criteria for a validator. Malformed parameters are ignored rather than failing the resolution.
+func parseMediaType(contentType string) (string, map[string]string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(stripMediaTypeParameters(contentType))), map[string]string{}
+	}
+	delete(params, "charset")
+	return mediaType, params
 }
 
 func stripMediaTypeParameters(contentType string) string {
@@ -22,12 +22,73 @@ func TestDraftContentValidatorResolver_MissingValidation(t *testing.T) {
 
 	validator, err := resolver.ValidatorForContentType("application/vnd.ft-upp-article+json; version=1.0; charset=utf-8")
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrDraftContentTypeNotSupported)
 	assert.Nil(t, validator)
 }
 
+func TestDraftContentValidatorResolver_ContentTypes(t *testing.T) {
+	ucv := NewDraftContentValidatorService("upp-article-endpoint", http.DefaultClient)
+	resolver := NewContentValidatorResolver(map[string]ContentValidator{
+		contentTypeArticle: ucv,
+		"application/vnd.ft-upp-content-placeholder+json": ucv,
+	})
+
+	assert.Equal(t, []string{contentTypeArticle, "application/vnd.ft-upp-content-placeholder+json"}, resolver.ContentTypes())
+
+	resolver.Reload(map[string]ContentValidator{})
+	assert.Empty(t, resolver.ContentTypes())
+}
+
 func cctOnlyResolverConfig(ucv ContentValidator) (contentTypeToValidator map[string]ContentValidator) {
 	return map[string]ContentValidator{
 		contentTypeArticle: ucv,
 	}
 }
+
+func TestDraftContentValidatorResolver_Reload(t *testing.T) {
+	ucv := NewDraftContentValidatorService("upp-article-endpoint", http.DefaultClient)
+	resolver := NewContentValidatorResolver(map[string]ContentValidator{})
+
+	resolver.Reload(cctOnlyResolverConfig(ucv))
+	validator, err := resolver.ValidatorForContentType(contentTypeArticle)
+	assert.NoError(t, err)
+	assert.Equal(t, ucv, validator)
+
+	resolver.Reload(map[string]ContentValidator{})
+	_, err = resolver.ValidatorForContentType(contentTypeArticle)
+	assert.Error(t, err, "content-types removed by a reload should no longer resolve")
+}
+
+func TestDraftContentValidatorResolver_MediaTypeParameters(t *testing.T) {
+	article := NewDraftContentValidatorService("upp-article-endpoint", http.DefaultClient)
+	articleV2 := NewDraftContentValidatorService("upp-article-v2-endpoint", http.DefaultClient)
+	placeholder := NewDraftContentValidatorService("upp-content-placeholder-endpoint", http.DefaultClient)
+	resolver := NewContentValidatorResolver(map[string]ContentValidator{
+		contentTypeArticle:                              article,
+		contentTypeArticle + "; version=2":              articleV2,
+		"application/json; profile=article":             article,
+		"application/json; profile=content-placeholder": placeholder,
+	})
+
+	tests := []struct {
+		contentType string
+		expected    ContentValidator
+	}{
+		{contentTypeArticle, article},
+		{contentTypeArticle + "; version=1.0; charset=utf-8", article},
+		{contentTypeArticle + "; charset=utf-8; version=2", articleV2},
+		{"Application/VND.FT-UPP-Article+JSON; Version=2", articleV2},
+		{"application/json; profile=article", article},
+		{`application/json; profile="content-placeholder"; charset=utf-8`, placeholder},
+	}
+	for _, test := range tests {
+		validator, err := resolver.ValidatorForContentType(test.contentType)
+		assert.NoError(t, err, test.contentType)
+		assert.Equal(t, test.expected, validator, test.contentType)
+	}
+
+	for _, contentType := range []string{"application/json", "application/json; profile=video", "text/plain"} {
+		_, err := resolver.ValidatorForContentType(contentType)
+		assert.ErrorIs(t, err, ErrDraftContentTypeNotSupported, contentType)
+	}
+}
@@ -81,6 +81,36 @@ func TestValidatorClientError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, err.(ValidatorError).StatusCode())
 }
 
+func TestValidatorClientErrorPayload(t *testing.T) {
+	contentUUID := uuid.New().String()
+	nativeBody := "{\"foo\":\"bar\"}"
+	validatorResponse := `{"error":"schema validation has failed","errors":[
+		{"field":"title","message":"title is required"},
+		{"instancePath":"/body","message":"must be a string"},
+		"unexpected property: foo"
+	]}`
+	server := mockValidatorHTTPServer(t, http.StatusBadRequest, nativeBody, validatorResponse)
+
+	testClient, err := fthttp.NewClient(fthttp.WithSysInfo("PAC", "awesome-service"))
+	assert.NoError(t, err)
+	m := NewDraftContentValidatorService(server.URL, testClient)
+
+	_, err = m.Validate(tidutils.TransactionAwareContext(context.Background(), testTID),
+		contentUUID,
+		io.NopCloser(strings.NewReader(nativeBody)),
+		"application/vnd.ft-upp-article+json; version=1.0; charset=utf-8",
+		logger.NewUPPLogger("test logger", "debug"),
+	)
+
+	assert.IsType(t, ValidatorError{}, err)
+	assert.JSONEq(t, validatorResponse, string(err.(ValidatorError).Payload()))
+	assert.Equal(t, []FieldError{
+		{Field: "title", Message: "title is required"},
+		{Field: "/body", Message: "must be a string"},
+		{Message: "unexpected property: foo"},
+	}, err.(ValidatorError).FieldErrors())
+}
+
 func TestValidatorBadContent(t *testing.T) {
 	contentUUID := uuid.New().String()
 	nativeBody := "{\"foo\":\"bar\"}"
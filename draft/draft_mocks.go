@@ -26,6 +26,12 @@ func (_md *MockDraftContentAPI) FetchValidatedContent(ctx context.Context, body
 	return r1, rErr
 }
 
+func (_md *MockDraftContentAPI) ContentTypes() []string {
+	ret := _md.Called()
+	r1 := ret.Get(0).([]string)
+	return r1
+}
+
 func (_md *MockDraftContentAPI) Endpoint() string {
 	ret := _md.Called()
 	r1 := ret.Get(0).(string)
@@ -56,6 +62,12 @@ func (_mr *MockValidatorResolver) ValidatorForContentType(contentType string) (C
 	return r0, rErr
 }
 
+func (_mr *MockValidatorResolver) ContentTypes() []string {
+	ret := _mr.Called()
+	r0 := ret.Get(0).([]string)
+	return r0
+}
+
 type MockValidator struct {
 	mock.Mock
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// suggestionsETag computes a strong entity tag for the suggestions served for a draft,
+// so that it changes whenever either the draft content or the suggestions payload changes.
+func suggestionsETag(content []byte, payload []byte) string {
+	h := sha256.New()
+	h.Write(content)
+	// separator, so that bytes moving between content and payload produce a different tag
+	h.Write([]byte{0})
+	h.Write(payload)
+
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
+
+// ifNoneMatch reports whether the If-None-Match header value matches the given entity tag.
+// As required for If-None-Match, entity tags are compared using the weak comparison function.
+func ifNoneMatch(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
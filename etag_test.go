@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestionsETag(t *testing.T) {
+	etag := suggestionsETag([]byte(`{"uuid":"a"}`), []byte(`{"suggestions":[]}`))
+
+	assert.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+	assert.Equal(t, etag, suggestionsETag([]byte(`{"uuid":"a"}`), []byte(`{"suggestions":[]}`)))
+	assert.NotEqual(t, etag, suggestionsETag([]byte(`{"uuid":"b"}`), []byte(`{"suggestions":[]}`)))
+	assert.NotEqual(t, etag, suggestionsETag([]byte(`{"uuid":"a"}`), []byte(`{"suggestions":[{}]}`)))
+	assert.NotEqual(t, suggestionsETag([]byte("ab"), []byte("c")), suggestionsETag([]byte("a"), []byte("bc")))
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	etag := `"abc"`
+
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{header: "", expected: false},
+		{header: `"abc"`, expected: true},
+		{header: `W/"abc"`, expected: true},
+		{header: `"xyz", "abc"`, expected: true},
+		{header: `"xyz"`, expected: false},
+		{header: `abc`, expected: false},
+		{header: `*`, expected: true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ifNoneMatch(test.header, etag), test.header)
+	}
+}
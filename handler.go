@@ -4,21 +4,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strings"
 
 	logger "github.com/Financial-Times/go-logger/v2"
 	tidutils "github.com/Financial-Times/transactionid-utils-go"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
 	"github.com/Financial-Times/draft-content-suggestions/draft"
+	"github.com/Financial-Times/draft-content-suggestions/jobs"
 	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+	"github.com/Financial-Times/draft-content-suggestions/tracing"
 )
 
 const (
-	contentTypeHeader = "Content-Type"
+	contentTypeHeader  = "Content-Type"
+	cacheControlHeader = "Cache-Control"
+	xCacheHeader       = "X-Cache"
+	acceptPostHeader   = "Accept-Post"
+	// failedProvidersHeader lists the suggestion providers missing from a partial response
+	failedProvidersHeader = "X-Suggestion-Providers-Failed"
+	// variantsHeader lists the variants the suggestion providers routed the draft to, as provider=variant pairs
+	variantsHeader = "X-Suggestion-Variants"
 )
 
 type BaseContent struct {
@@ -29,6 +42,10 @@ type requestHandler struct {
 	dca draft.ContentAPI
 	sua suggestions.UmbrellaAPI
 	log *logger.UPPLogger
+	// legacyErrors makes the error responses use the {"message": ...} shape instead of problem details
+	legacyErrors bool
+	// jobPool runs the suggestions requested asynchronously
+	jobPool *jobs.Pool
 }
 
 func (rh *requestHandler) draftContentSuggestionsRequest(writer http.ResponseWriter, request *http.Request) {
@@ -39,41 +56,43 @@ func (rh *requestHandler) draftContentSuggestionsRequest(writer http.ResponseWri
 	if err != nil {
 		msg := "Invalid UUID"
 		log.WithError(err).Warn(msg)
-		_ = WriteJSONMessage(writer, http.StatusBadRequest, msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidUUID, msg))
 		return
 	}
 
-	ctx := NewContextFromRequest(request)
-	content, err := rh.dca.FetchDraftContent(ctx, uuid)
-	if err == draft.ErrDraftNotMappable {
-		msg := "Could not provide suggestions for content, as we are unable to map it"
-		log.WithError(err).Info(msg)
-		_ = WriteJSONMessage(writer, http.StatusUnprocessableEntity, msg)
-		return
-	}
+	filter, err := suggestions.NewFilterFromQuery(request.URL.Query())
 	if err != nil {
-		msg := "Draft content api retrieval has failed."
-		log.WithError(err).Error(msg)
-		_ = WriteJSONMessage(writer, http.StatusInternalServerError, msg)
+		msg := "Invalid suggestions filter"
+		log.WithError(err).Warn(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidFilter, fmt.Sprintf("%s: %s", msg, err.Error())))
 		return
 	}
-	if content == nil {
-		msg := "No draft content for UUID"
-		log.Warn(msg)
-		_ = WriteJSONMessage(writer, http.StatusNotFound, msg)
+
+	ctx, meta := suggestions.ContextWithMetadata(NewContextFromRequest(request))
+	content, suggestion, prob := rh.fetchDraftSuggestions(ctx, uuid, log)
+	if prob != nil {
+		_ = rh.writeProblem(writer, request, prob)
 		return
 	}
 
-	suggestion, err := rh.sua.FetchSuggestions(ctx, content)
+	payload := &bytes.Buffer{}
+	err = filter.Apply(suggestion).Encode(payload)
 	if err != nil {
-		msg := "Suggestions umbrella api access has failed"
+		msg := "Failed encoding suggestions"
 		log.WithError(err).Error(msg)
-		_ = WriteJSONMessage(writer, http.StatusServiceUnavailable, msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInternal, msg))
+		return
+	}
+
+	setSuggestionsHeaders(writer, meta)
+	etag := suggestionsETag(content, payload.Bytes())
+	writer.Header().Set(etagHeader, etag)
+	if ifNoneMatch(request.Header.Get(ifNoneMatchHeader), etag) {
+		writer.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	writer.Header().Set("Content-Type", "application/json")
-	_, err = writer.Write(suggestion)
+	_, err = writer.Write(payload.Bytes())
 	if err != nil {
 		// could be related to intermittent/temporary network issues
 		// or original Tagme request is no more waiting for a response.
@@ -84,18 +103,33 @@ func (rh *requestHandler) draftContentSuggestionsRequest(writer http.ResponseWri
 func (rh *requestHandler) getDraftSuggestionsForContent(writer http.ResponseWriter, request *http.Request) {
 	log := rh.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(request))
 
+	filter, err := suggestions.NewFilterFromQuery(request.URL.Query())
+	if err != nil {
+		msg := "Invalid suggestions filter"
+		log.WithError(err).Warn(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidFilter, fmt.Sprintf("%s: %s", msg, err.Error())))
+		return
+	}
+	async, err := isAsync(request)
+	if err != nil {
+		msg := "Invalid async mode"
+		log.WithError(err).Warn(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidQuery, fmt.Sprintf("%s: %s", msg, err.Error())))
+		return
+	}
+
 	requestBody, err := io.ReadAll(request.Body)
 	if err != nil {
 		msg := "error while reading request body"
 		log.WithError(err).Warn(err)
-		_ = WriteJSONMessage(writer, http.StatusBadRequest, msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidBody, msg))
 		return
 	}
 
 	if len(requestBody) == 0 {
 		msg := "content body is missing from the request"
 		log.Error(msg)
-		_ = WriteJSONMessage(writer, http.StatusBadRequest, msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidBody, msg))
 		return
 	}
 
@@ -104,39 +138,37 @@ func (rh *requestHandler) getDraftSuggestionsForContent(writer http.ResponseWrit
 	if err != nil {
 		msg := "error while unmarshalling uuid from the request payload"
 		log.Error(msg)
-		_ = WriteJSONMessage(writer, http.StatusBadRequest, msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidBody, msg))
+		return
 	}
 
 	err = ValidateUUID(baseContent.UUID)
 	if err != nil {
 		msg := "Invalid payload UUID"
 		log.WithError(err).Warn(msg)
-		_ = WriteJSONMessage(writer, http.StatusBadRequest, msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInvalidUUID, msg))
 		return
 	}
 	log = log.WithUUID(baseContent.UUID)
 
-	contentType := request.Header.Get(contentTypeHeader)
-	ctx := NewContextFromRequest(request)
-
-	content, err := rh.dca.FetchValidatedContent(ctx, bytes.NewReader(requestBody), baseContent.UUID, contentType, rh.log)
-	if err != nil {
-		msg := "failed while validating content"
-		log.WithError(err).Warn(msg)
-		_ = WriteJSONMessage(writer, http.StatusBadRequest, fmt.Sprintf("%s: %s", msg, err.Error()))
+	if async {
+		rh.submitSuggestionsJob(writer, request, requestBody, baseContent.UUID, filter, log)
 		return
 	}
 
-	suggestion, err := rh.sua.FetchSuggestions(ctx, content)
-	if err != nil {
-		msg := "Suggestions umbrella api access has failed"
-		log.WithError(err).Error(msg)
-		_ = WriteJSONMessage(writer, http.StatusServiceUnavailable, msg)
+	contentType := request.Header.Get(contentTypeHeader)
+	ctx, meta := suggestions.ContextWithMetadata(NewContextFromRequest(request))
+
+	suggestion, prob := rh.fetchContentSuggestions(ctx, requestBody, baseContent.UUID, contentType, log)
+	if prob != nil {
+		if prob.Status == http.StatusUnsupportedMediaType {
+			writer.Header().Set(acceptPostHeader, strings.Join(rh.dca.ContentTypes(), ", "))
+		}
+		_ = rh.writeProblem(writer, request, prob)
 		return
 	}
 
-	writer.Header().Set("Content-Type", "application/json")
-	_, err = writer.Write(suggestion)
+	err = writeSuggestions(writer, meta, filter.Apply(suggestion))
 	if err != nil {
 		// could be related to intermittent/temporary network issues
 		// or original Tagme request is no more waiting for a response.
@@ -144,23 +176,141 @@ func (rh *requestHandler) getDraftSuggestionsForContent(writer http.ResponseWrit
 	}
 }
 
-type message struct {
-	Message string `json:"message"`
+// fetchDraftSuggestions fetches the draft with the given uuid and its suggestions.
+func (rh *requestHandler) fetchDraftSuggestions(ctx context.Context, uuid string, log *logger.LogEntry) ([]byte, *suggestions.SuggestionsResponse, *problem) {
+	content, err := rh.dca.FetchDraftContent(ctx, uuid)
+	if err == draft.ErrDraftNotMappable {
+		msg := "Could not provide suggestions for content, as we are unable to map it"
+		log.WithError(err).Info(msg)
+		return nil, nil, newProblem(problemDraftNotMappable, msg)
+	}
+	if errors.Is(err, breaker.ErrOpen) {
+		msg := "Draft content api is temporarily unavailable"
+		log.WithError(err).Warn(msg)
+		return nil, nil, newProblem(problemDependencyUnavailable, msg)
+	}
+	if err != nil {
+		msg := "Draft content api retrieval has failed."
+		log.WithError(err).Error(msg)
+		return nil, nil, newProblem(problemDraftContentFailed, msg)
+	}
+	if content == nil {
+		msg := "No draft content for UUID"
+		log.Warn(msg)
+		return nil, nil, newProblem(problemDraftNotFound, msg)
+	}
+
+	suggestion, prob := rh.fetchSuggestions(ctx, content, log)
+	if prob != nil {
+		return nil, nil, prob
+	}
+
+	return content, suggestion, nil
+}
+
+// fetchContentSuggestions validates the given content and fetches its suggestions.
+func (rh *requestHandler) fetchContentSuggestions(ctx context.Context, body []byte, uuid string, contentType string, log *logger.LogEntry) (*suggestions.SuggestionsResponse, *problem) {
+	content, err := rh.dca.FetchValidatedContent(ctx, bytes.NewReader(body), uuid, contentType, rh.log)
+	if err != nil {
+		return nil, validationProblem(err, log)
+	}
+
+	return rh.fetchSuggestions(ctx, content, log)
+}
+
+// validationProblem tells apart the drafts the validator rejected from the failures to reach the validator.
+func validationProblem(err error, log *logger.LogEntry) *problem {
+	var validatorErr draft.ValidatorError
+	isValidatorErr := errors.As(err, &validatorErr)
+	rejected := fmt.Sprintf("failed while validating content: %s", err.Error())
+
+	switch {
+	case errors.Is(err, draft.ErrDraftContentTypeNotSupported):
+		log.WithError(err).Warn("Unsupported content type")
+		return newProblem(problemUnsupportedMediaType, rejected).withValidatorError(err)
+	case errors.Is(err, draft.ErrDraftNotValid):
+		log.WithError(err).Info("Could not provide suggestions for content, as the validator is unable to map it")
+		return newProblem(problemDraftNotMappable, rejected).withValidatorError(err)
+	case isValidatorErr && validatorErr.StatusCode() == http.StatusBadRequest:
+		log.WithError(err).Warn("failed while validating content")
+		return newProblem(problemValidationFailed, rejected).withValidatorError(err)
+	default:
+		return dependencyProblem(err, "Validator", log)
+	}
+}
+
+// dependencyProblem tells apart the dependencies which timed out, the ones which are temporarily unavailable, and
+// the ones which failed otherwise, such as with an unexpected response.
+func dependencyProblem(err error, dependency string, log *logger.LogEntry) *problem {
+	var statusErr interface{ StatusCode() int }
+	isStatusErr := errors.As(err, &statusErr)
+
+	switch {
+	case isTimeout(err):
+		msg := dependency + " did not respond in time"
+		log.WithError(err).Error(msg)
+		return newProblem(problemDependencyTimeout, msg)
+	case errors.Is(err, breaker.ErrOpen) || (isStatusErr && statusErr.StatusCode() == http.StatusServiceUnavailable):
+		msg := dependency + " is temporarily unavailable"
+		log.WithError(err).Warn(msg)
+		return newProblem(problemDependencyUnavailable, msg)
+	default:
+		msg := dependency + " access has failed"
+		log.WithError(err).Error(msg)
+		return newProblem(problemDependencyFailed, msg)
+	}
 }
 
-// WriteJSONMessage writes the msg provided as encoded json with the proper content type header added.
-func WriteJSONMessage(w http.ResponseWriter, status int, msg string) error {
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(status)
+// isTimeout tells whether the error is due to a deadline or a timeout of the HTTP client.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
 
-	enc := json.NewEncoder(w)
-	return enc.Encode(&message{Message: msg})
+func (rh *requestHandler) fetchSuggestions(ctx context.Context, content []byte, log *logger.LogEntry) (*suggestions.SuggestionsResponse, *problem) {
+	suggestion, err := rh.sua.FetchSuggestions(ctx, content)
+	if err != nil {
+		return nil, dependencyProblem(err, "Suggestions umbrella api", log)
+	}
+	meta := suggestions.MetadataFromContext(ctx)
+	if failed := meta.FailedProviders(); len(failed) > 0 {
+		log.WithField("failedProviders", failed).Warn("Suggestions are missing the ones of failed providers")
+	}
+	if variants := meta.Variants(); len(variants) > 0 {
+		log.WithField("variants", variants).Info("Suggestions were routed to variants")
+	}
+
+	return suggestion, nil
+}
+
+// writeSuggestions writes the suggestions response along with the headers describing how it was produced.
+func writeSuggestions(w http.ResponseWriter, meta *suggestions.Metadata, resp *suggestions.SuggestionsResponse) error {
+	setSuggestionsHeaders(w, meta)
+	return resp.Encode(w)
+}
+
+func setSuggestionsHeaders(w http.ResponseWriter, meta *suggestions.Metadata) {
+	w.Header().Set(contentTypeHeader, "application/json")
+	// suggestions depend on the current state of the draft, so clients should always revalidate them
+	w.Header().Set(cacheControlHeader, "private, no-cache")
+	if status := meta.CacheStatus(); status != "" {
+		w.Header().Set(xCacheHeader, string(status))
+	}
+	if failed := meta.FailedProviders(); len(failed) > 0 {
+		w.Header().Set(failedProvidersHeader, strings.Join(failed, ", "))
+	}
+	if variants := meta.Variants(); len(variants) > 0 {
+		w.Header().Set(variantsHeader, strings.Join(variants, ", "))
+	}
 }
 
 // NewContextFromRequest provides a new context including a trxId
 // from the request or if missing, a brand new trxId.
 func NewContextFromRequest(r *http.Request) context.Context {
-	return tidutils.TransactionAwareContext(r.Context(), tidutils.GetTransactionIDFromRequest(r))
+	tid := tidutils.GetTransactionIDFromRequest(r)
+	// the transaction ID goes along with the trace, so that one can be found from the other
+	tracing.SetTransactionID(r.Context(), tid)
+	return tidutils.TransactionAwareContext(r.Context(), tid)
 }
 
 // ValidateUUID checks the uuid string for supported formats
@@ -2,20 +2,26 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	logger "github.com/Financial-Times/go-logger/v2"
 	transactionidutils "github.com/Financial-Times/transactionid-utils-go"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	metrics "github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
 	"github.com/Financial-Times/draft-content-suggestions/config"
 	"github.com/Financial-Times/draft-content-suggestions/draft"
 	"github.com/Financial-Times/draft-content-suggestions/mocks"
@@ -26,72 +32,155 @@ import (
 func TestGetDraftSuggestionsForContent(t *testing.T) {
 	tests := []struct {
 		name                       string
-		retMockSuggestionsResponse []byte
+		retMockSuggestionsResponse *suggestions.SuggestionsResponse
 		retMockSuggestionsErr      error
 		retMockContentAPIResponse  []byte
 		retMockContentAPIError     error
+		legacyErrors               bool
 		expectedStatus             int
+		expectedContentType        string
+		expectedAcceptPost         string
 		expectedError              error
+		query                      string
 		payload                    []byte
 		expectedContentResult      []byte
 	}{
 		{
-			name:                       "Successful fetch",
-			expectedStatus:             http.StatusOK,
-			payload:                    []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
-			retMockContentAPIResponse:  []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
-			retMockSuggestionsResponse: []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
-			expectedContentResult:      []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			name:                      "Successful fetch",
+			expectedStatus:            http.StatusOK,
+			payload:                   []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIResponse: []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockSuggestionsResponse: &suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{
+				{ID: "http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a", Predicate: "http://www.ft.com/ontology/annotation/about"},
+			}},
+			expectedContentResult: []byte(`{"suggestions":[{"id":"http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a","predicate":"http://www.ft.com/ontology/annotation/about"}]}
+`),
+		},
+		{
+			name:                      "Successful fetch with filter",
+			expectedStatus:            http.StatusOK,
+			query:                     "?predicate=mentions",
+			payload:                   []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIResponse: []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockSuggestionsResponse: &suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{
+				{ID: "http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a", Predicate: "http://www.ft.com/ontology/annotation/about"},
+				{ID: "http://www.ft.com/thing/9a5e3b4a-55da-498c-816f-9c534e1392bd", Predicate: "http://www.ft.com/ontology/annotation/mentions"},
+			}},
+			expectedContentResult: []byte(`{"suggestions":[{"id":"http://www.ft.com/thing/9a5e3b4a-55da-498c-816f-9c534e1392bd","predicate":"http://www.ft.com/ontology/annotation/mentions"}]}
+`),
 		},
 		{
 			name:           "Empty payload",
 			expectedStatus: http.StatusBadRequest,
-			expectedContentResult: []byte(`{"message":"content body is missing from the request"}
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:invalid-body","title":"Invalid request body","status":400,"detail":"content body is missing from the request","transactionId":"tid_test"}
 `),
 		},
 		{
 			name:           "Invalid uuid",
 			expectedStatus: http.StatusBadRequest,
 			payload:        []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74dzzz"}`),
-			expectedContentResult: []byte(`{"message":"Invalid payload UUID"}
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:invalid-uuid","title":"Invalid UUID","status":400,"detail":"Invalid payload UUID","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                   "FetchValidatedContent unsupported content type",
+			expectedStatus:         http.StatusUnsupportedMediaType,
+			expectedAcceptPost:     "application/vnd.ft-upp-article+json, application/vnd.ft-upp-live-blog-post+json",
+			payload:                []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIError: fmt.Errorf("%w: simulated error", draft.ErrDraftContentTypeNotSupported),
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:unsupported-media-type","title":"Unsupported draft content type","status":415,"detail":"failed while validating content: draft content-type is invalid: simulated error","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                   "FetchValidatedContent mapping failure",
+			expectedStatus:         http.StatusUnprocessableEntity,
+			payload:                []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIError: fmt.Errorf("%w: simulated error", draft.ErrDraftNotValid),
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:draft-not-mappable","title":"Draft content cannot be mapped","status":422,"detail":"failed while validating content: draft content is invalid: simulated error","transactionId":"tid_test"}
 `),
 		},
 		{
-			name:                   "FetchValidatedContent error case",
-			expectedStatus:         http.StatusBadRequest,
+			name:                   "FetchValidatedContent validator outage",
+			expectedStatus:         http.StatusBadGateway,
 			payload:                []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
 			retMockContentAPIError: errors.New("simulated error"),
-			expectedContentResult: []byte(`{"message":"failed while validating content: simulated error"}
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:dependency-failed","title":"Dependency failed","status":502,"detail":"Validator access has failed","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                   "FetchValidatedContent validator circuit open",
+			expectedStatus:         http.StatusServiceUnavailable,
+			payload:                []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIError: fmt.Errorf("validating: %w", breaker.ErrOpen),
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:dependency-unavailable","title":"Dependency temporarily unavailable","status":503,"detail":"Validator is temporarily unavailable","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                   "FetchValidatedContent validator timeout",
+			expectedStatus:         http.StatusGatewayTimeout,
+			payload:                []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIError: fmt.Errorf("validating: %w", context.DeadlineExceeded),
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:dependency-timeout","title":"Dependency timed out","status":504,"detail":"Validator did not respond in time","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                   "Legacy error response",
+			legacyErrors:           true,
+			expectedStatus:         http.StatusUnprocessableEntity,
+			expectedContentType:    "application/json",
+			payload:                []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIError: fmt.Errorf("%w: simulated error", draft.ErrDraftNotValid),
+			expectedContentResult: []byte(`{"message":"failed while validating content: draft content is invalid: simulated error"}
 `),
 		},
 		{
 			name:                      "FetchSuggestions error case",
-			expectedStatus:            http.StatusServiceUnavailable,
+			expectedStatus:            http.StatusBadGateway,
 			payload:                   []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
 			retMockContentAPIResponse: []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
 			retMockSuggestionsErr:     errors.New("simulated error"),
-			expectedContentResult: []byte(`{"message":"Suggestions umbrella api access has failed"}
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:dependency-failed","title":"Dependency failed","status":502,"detail":"Suggestions umbrella api access has failed","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                      "FetchSuggestions circuit open",
+			expectedStatus:            http.StatusServiceUnavailable,
+			payload:                   []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIResponse: []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockSuggestionsErr:     fmt.Errorf("fetching suggestions: %w", breaker.ErrOpen),
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:dependency-unavailable","title":"Dependency temporarily unavailable","status":503,"detail":"Suggestions umbrella api is temporarily unavailable","transactionId":"tid_test"}
+`),
+		},
+		{
+			name:                      "FetchSuggestions timeout",
+			expectedStatus:            http.StatusGatewayTimeout,
+			payload:                   []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockContentAPIResponse: []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`),
+			retMockSuggestionsErr:     fmt.Errorf("fetching suggestions: %w", context.DeadlineExceeded),
+			expectedContentResult: []byte(`{"type":"urn:draft-content-suggestions:problem:dependency-timeout","title":"Dependency timed out","status":504,"detail":"Suggestions umbrella api did not respond in time","transactionId":"tid_test"}
 `),
 		},
 	}
 
 	retMockSuggestions := &suggestions.MockSuggestionsUmbrellaAPI{}
 	retMockContentAPI := &draft.MockDraftContentAPI{}
+	retMockContentAPI.On("ContentTypes").Return([]string{"application/vnd.ft-upp-article+json", "application/vnd.ft-upp-live-blog-post+json"})
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			log := logger.NewUnstructuredLogger()
 
-			rh := requestHandler{retMockContentAPI, retMockSuggestions, log}
+			rh := requestHandler{dca: retMockContentAPI, sua: retMockSuggestions, log: log, legacyErrors: test.legacyErrors}
 
 			r := mux.NewRouter()
 			r.HandleFunc("/drafts/content/suggestions", rh.getDraftSuggestionsForContent)
 			ts := httptest.NewServer(r)
 
 			defer ts.Close()
-			req, err := http.NewRequest(http.MethodPost, ts.URL+"/drafts/content/suggestions", bytes.NewReader(test.payload))
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/drafts/content/suggestions"+test.query, bytes.NewReader(test.payload))
 			if err != nil {
 				t.Fatal(err)
 			}
+			req.Header.Set(transactionidutils.TransactionIDHeader, "tid_test")
 
 			retMockContentAPI.On("FetchValidatedContent", mock.Anything, bytes.NewReader(test.payload), mock.Anything, "", log).Return(test.retMockContentAPIResponse, test.retMockContentAPIError).Once()
 			defer retMockContentAPI.On("FetchValidatedContent", mock.Anything, bytes.NewReader(test.payload), mock.Anything, "", log).Unset()
@@ -120,6 +209,14 @@ func TestGetDraftSuggestionsForContent(t *testing.T) {
 				t.Fatalf("expected status code: %v, but got: %v", test.expectedStatus, resp.StatusCode)
 			}
 
+			if test.expectedContentType != "" && resp.Header.Get("Content-Type") != test.expectedContentType {
+				t.Errorf("expected content type: %s, but got: %s", test.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+
+			if resp.Header.Get("Accept-Post") != test.expectedAcceptPost {
+				t.Errorf("expected Accept-Post: %s, but got: %s", test.expectedAcceptPost, resp.Header.Get("Accept-Post"))
+			}
+
 			respBody, err := io.ReadAll(resp.Body)
 			if err != nil {
 				t.Fatal(err)
@@ -143,11 +240,17 @@ func TestRequestHandlerSuccess(t *testing.T) {
 
 func TestRequestHandlerContentNotFound(t *testing.T) {
 	resp, err := handleTestRequest("/drafts/content/" + mocks.MissingMockContentUUID + "/suggestions")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
 
-	resp.Body.Close()
+	var p problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
 
-	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "urn:draft-content-suggestions:problem:draft-not-found", p.Type)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.NotEmpty(t, p.TransactionID)
 }
 
 func TestRequestHandlerContentNotMappable(t *testing.T) {
@@ -170,6 +273,27 @@ func TestRequestHandlerContentInvalidUUID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
 
+func TestRequestHandlerFilteredSuggestions(t *testing.T) {
+	resp, err := handleTestRequest("/drafts/content/" + mocks.ValidMockContentUUID + "/suggestions?type=Person&predicate=!mentions")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "{\"suggestions\":[]}\n", string(body))
+}
+
+func TestRequestHandlerInvalidFilter(t *testing.T) {
+	resp, err := handleTestRequest("/drafts/content/" + mocks.ValidMockContentUUID + "/suggestions?predicate=!")
+	assert.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 const (
 	validEndpoint   = "http://some.valid.url/with/sub/resources:8080"
 	invalidEndpoint = "/missing.com/scheme/or/uri/type"
@@ -212,12 +336,15 @@ func handleTestRequest(urlpath string) (resp *http.Response, err error) {
 		log.WithError(err).Fatal("unable to read r/w YAML configuration")
 	}
 
-	contentTypeMapping := draft.BuildContentTypeMapping(validatorConfig, http.DefaultClient, log)
+	contentTypeMapping, err := draft.BuildContentTypeMapping(validatorConfig, draft.SharedHTTPClient(http.DefaultClient), log)
+	if err != nil {
+		log.WithError(err).Fatal("unable to build the content-type mapping")
+	}
 	resolver := draft.NewContentValidatorResolver(contentTypeMapping)
 	contentAPI, _ := draft.NewContentAPI(draftContentTestServer.URL+"/drafts/content", draftContentTestServer.URL+"/__gtg", http.DefaultClient, http.DefaultClient, resolver)
-	umbrellaAPI, _ := suggestions.NewUmbrellaAPI(umbrellaTestServer.URL, umbrellaTestServer.URL+"/__gtg", suggestions.TestUsername, suggestions.TestPassword, http.DefaultClient, http.DefaultClient)
+	umbrellaAPI, _ := suggestions.NewUmbrellaAPI(umbrellaTestServer.URL+"/content/suggest", umbrellaTestServer.URL+"/content/suggest/__gtg", suggestions.TestUsername, suggestions.TestPassword, http.DefaultClient, http.DefaultClient)
 
-	rh := requestHandler{contentAPI, umbrellaAPI, log}
+	rh := requestHandler{dca: contentAPI, sua: umbrellaAPI, log: log}
 
 	r := mux.NewRouter()
 	r.HandleFunc("/drafts/content/{uuid}/suggestions", rh.draftContentSuggestionsRequest)
@@ -227,3 +354,244 @@ func handleTestRequest(urlpath string) (resp *http.Response, err error) {
 
 	return http.Get(ts.URL + urlpath)
 }
+
+func TestRequestHandlerCacheHeaders(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, "36320eb6-5617-4d12-9750-1907690e74db").Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil).Once()
+
+	cachedAPI := suggestions.NewCachedUmbrellaAPI(umbrellaAPI, suggestions.NewLRUCache(10, time.Minute), metrics.NewRegistry())
+	rh := requestHandler{dca: contentAPI, sua: cachedAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", rh.draftContentSuggestionsRequest)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	for _, expectedCacheStatus := range []string{"MISS", "HIT"} {
+		resp, err := http.Get(ts.URL + "/drafts/content/36320eb6-5617-4d12-9750-1907690e74db/suggestions")
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, expectedCacheStatus, resp.Header.Get("X-Cache"))
+		assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+	}
+	umbrellaAPI.AssertExpectations(t)
+}
+
+func TestRequestHandlerFailedProvidersHeader(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, "36320eb6-5617-4d12-9750-1907690e74db").Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{{ID: "id", Predicate: "about"}}}, nil)
+	extractorAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	extractorAPI.On("FetchSuggestions", mock.Anything, content).Return((*suggestions.SuggestionsResponse)(nil), errors.New("boom"))
+
+	registry, err := suggestions.NewProviderRegistry([]suggestions.Provider{{Name: "umbrella", API: umbrellaAPI}, {Name: "extractor", API: extractorAPI}})
+	assert.NoError(t, err)
+	rh := requestHandler{dca: contentAPI, sua: registry, log: logger.NewUPPLogger("Test", "PANIC")}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", rh.draftContentSuggestionsRequest)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/drafts/content/36320eb6-5617-4d12-9750-1907690e74db/suggestions")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "extractor", resp.Header.Get("X-Suggestion-Providers-Failed"))
+	assert.JSONEq(t, `{"suggestions":[{"id":"id","predicate":"about","providers":["umbrella"]}]}`, string(body))
+}
+
+func TestRequestHandlerVariantsHeader(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, "36320eb6-5617-4d12-9750-1907690e74db").Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil)
+
+	router, err := suggestions.NewWeightedRouter("umbrella", []suggestions.Variant{
+		{Name: "current", API: umbrellaAPI, Weight: 1},
+		{Name: "candidate", API: umbrellaAPI, Weight: 1},
+	}, metrics.NewRegistry())
+	assert.NoError(t, err)
+	rh := requestHandler{dca: contentAPI, sua: router, log: logger.NewUPPLogger("Test", "PANIC")}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", rh.draftContentSuggestionsRequest)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	var variants []string
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(ts.URL + "/drafts/content/36320eb6-5617-4d12-9750-1907690e74db/suggestions")
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Regexp(t, "^umbrella=(current|candidate)$", resp.Header.Get("X-Suggestion-Variants"))
+		variants = append(variants, resp.Header.Get("X-Suggestion-Variants"))
+	}
+	assert.Equal(t, variants[0], variants[1], "the draft is always routed to the same variant")
+}
+
+func TestRequestHandlerVariantsHeaderOnCacheHit(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, "36320eb6-5617-4d12-9750-1907690e74db").Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil).Once()
+
+	registry := metrics.NewRegistry()
+	router, err := suggestions.NewWeightedRouter("umbrella", []suggestions.Variant{
+		{Name: "current", API: umbrellaAPI, Weight: 1},
+		{Name: "candidate", API: umbrellaAPI, Weight: 1},
+	}, registry)
+	assert.NoError(t, err)
+	cachedAPI := suggestions.NewCachedUmbrellaAPI(router, suggestions.NewLRUCache(10, time.Minute), registry)
+	rh := requestHandler{dca: contentAPI, sua: cachedAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", rh.draftContentSuggestionsRequest)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	var variants []string
+	for _, expectedCacheStatus := range []string{"MISS", "HIT"} {
+		resp, err := http.Get(ts.URL + "/drafts/content/36320eb6-5617-4d12-9750-1907690e74db/suggestions")
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, expectedCacheStatus, resp.Header.Get("X-Cache"))
+		assert.Regexp(t, "^umbrella=(current|candidate)$", resp.Header.Get("X-Suggestion-Variants"))
+		variants = append(variants, resp.Header.Get("X-Suggestion-Variants"))
+	}
+	assert.Equal(t, variants[0], variants[1], "the cached suggestions keep the variant they were routed to")
+	umbrellaAPI.AssertExpectations(t)
+
+	variant := strings.TrimPrefix(variants[0], "umbrella=")
+	assert.Equal(t, int64(2), registry.Get("suggestions.variants.umbrella."+variant+".calls").(metrics.Counter).Count())
+}
+
+func TestRequestHandlerConditionalGet(t *testing.T) {
+	draftContentTestServer := mocks.NewDraftContentTestServer(true)
+	defer draftContentTestServer.Close()
+	umbrellaTestServer := mocks.NewUmbrellaTestServer(true)
+	defer umbrellaTestServer.Close()
+
+	contentAPI, _ := draft.NewContentAPI(draftContentTestServer.URL+"/drafts/content", draftContentTestServer.URL+"/__gtg", http.DefaultClient, http.DefaultClient, draft.NewContentValidatorResolver(nil))
+	umbrellaAPI, _ := suggestions.NewUmbrellaAPI(umbrellaTestServer.URL+"/content/suggest", umbrellaTestServer.URL+"/content/suggest/__gtg", suggestions.TestUsername, suggestions.TestPassword, http.DefaultClient, http.DefaultClient)
+	rh := requestHandler{dca: contentAPI, sua: umbrellaAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", rh.draftContentSuggestionsRequest)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	get := func(ifNoneMatch string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/drafts/content/"+mocks.ValidMockContentUUID+"/suggestions", nil)
+		assert.NoError(t, err)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := get("")
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, etag)
+
+	resp = get(etag)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	assert.Empty(t, body)
+
+	resp = get(`"stale"`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+}
+
+func TestRequestHandlerValidatorErrorDetails(t *testing.T) {
+	validatorResponse := `{"error":"schema validation has failed","errors":[{"field":"title","message":"title is required"}]}`
+	validatorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(validatorResponse))
+	}))
+	defer validatorServer.Close()
+
+	resolver := draft.NewContentValidatorResolver(map[string]draft.ContentValidator{
+		"application/vnd.ft-upp-article+json": draft.NewDraftContentValidatorService(validatorServer.URL, http.DefaultClient),
+	})
+	contentAPI, _ := draft.NewContentAPI("http://localhost/drafts/content", "http://localhost/__gtg", http.DefaultClient, http.DefaultClient, resolver)
+	rh := requestHandler{dca: contentAPI, sua: &suggestions.MockSuggestionsUmbrellaAPI{}, log: logger.NewUPPLogger("Test", "PANIC")}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/suggestions", rh.getDraftSuggestionsForContent)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/drafts/content/suggestions", "application/vnd.ft-upp-article+json",
+		bytes.NewReader([]byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var p problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Equal(t, "urn:draft-content-suggestions:problem:draft-not-mappable", p.Type)
+	assert.JSONEq(t, validatorResponse, string(p.Validator))
+	assert.Equal(t, []draft.FieldError{{Field: "title", Message: "title is required"}}, p.Errors)
+}
+
+func TestRequestHandlerUmbrellaErrorStatuses(t *testing.T) {
+	tests := map[int]int{
+		http.StatusInternalServerError: http.StatusBadGateway,
+		http.StatusBadGateway:          http.StatusBadGateway,
+		http.StatusServiceUnavailable:  http.StatusServiceUnavailable,
+		http.StatusUnauthorized:        http.StatusBadGateway,
+	}
+	for umbrellaStatus, expectedStatus := range tests {
+		t.Run(http.StatusText(umbrellaStatus), func(t *testing.T) {
+			umbrellaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(umbrellaStatus)
+			}))
+			defer umbrellaServer.Close()
+
+			content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+			contentAPI := &draft.MockDraftContentAPI{}
+			contentAPI.On("FetchValidatedContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(content, nil)
+			umbrellaAPI, err := suggestions.NewUmbrellaAPI(umbrellaServer.URL+"/content/suggest", umbrellaServer.URL+"/__gtg", suggestions.TestUsername, suggestions.TestPassword, http.DefaultClient, http.DefaultClient)
+			assert.NoError(t, err)
+			rh := requestHandler{dca: contentAPI, sua: umbrellaAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+
+			r := mux.NewRouter()
+			r.HandleFunc("/drafts/content/suggestions", rh.getDraftSuggestionsForContent)
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			resp, err := http.Post(ts.URL+"/drafts/content/suggestions", "application/vnd.ft-upp-article+json", bytes.NewReader(content))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, expectedStatus, resp.StatusCode)
+		})
+	}
+}
@@ -3,12 +3,15 @@ package health
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 	logger "github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/service-status-go/gtg"
 
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
 	"github.com/Financial-Times/draft-content-suggestions/config"
 	"github.com/Financial-Times/draft-content-suggestions/draft"
 	"github.com/Financial-Times/draft-content-suggestions/suggestions"
@@ -18,6 +21,7 @@ const DefaultHealthPath = "/__health"
 
 type Service struct {
 	config       *appConfig
+	mu           sync.RWMutex
 	healthChecks []fthealth.Check
 	gtgChecks    []gtg.StatusChecker
 	contentAPI   draft.ContentAPI
@@ -38,7 +42,8 @@ type ExternalService interface {
 
 func NewService(appSystemCode string, appName string,
 	appDescription string, contentAPI draft.ContentAPI,
-	umbrellaAPI suggestions.UmbrellaAPI, hcConfig *config.Config, services []ExternalService, log *logger.UPPLogger) (*Service, error) {
+	umbrellaAPI suggestions.UmbrellaAPI, hcConfig *config.Config, services []ExternalService,
+	breakers []*breaker.Breaker, log *logger.UPPLogger) (*Service, error) {
 	hc := &Service{
 		config: &appConfig{
 			appSystemCode:  appSystemCode,
@@ -50,8 +55,6 @@ func NewService(appSystemCode string, appName string,
 		log:         log,
 	}
 
-	hc.healthChecks = []fthealth.Check{hc.draftContentCheck(), hc.suggestionsCheck()}
-
 	draftContentCheck := func() gtg.Status {
 		return gtgCheck(hc.draftContentChecker)
 	}
@@ -62,10 +65,38 @@ func NewService(appSystemCode string, appName string,
 	gtgChecks := append(hc.gtgChecks, draftContentCheck, suggestionsCheck)
 	hc.gtgChecks = gtgChecks
 
+	if err := hc.Reload(hcConfig, services, breakers); err != nil {
+		return nil, err
+	}
+
+	return hc, nil
+}
+
+// Reload replaces the validator and circuit breaker checks with the ones for the given configuration.
+// The current checks are kept if any of the new ones cannot be built.
+func (s *Service) Reload(hcConfig *config.Config, services []ExternalService, breakers []*breaker.Breaker) error {
+	checks, err := s.Prepare(hcConfig, services, breakers)
+	if err != nil {
+		return err
+	}
+	s.Publish(checks)
+	return nil
+}
+
+// Checks are the health checks built for a configuration, not yet published.
+type Checks struct {
+	checks []fthealth.Check
+}
+
+// Prepare builds the validator and circuit breaker checks for the given configuration, without replacing the
+// current ones, so that they can be published along with the rest of the configuration.
+func (s *Service) Prepare(hcConfig *config.Config, services []ExternalService, breakers []*breaker.Breaker) (Checks, error) {
+	checks := []fthealth.Check{s.draftContentCheck(), s.suggestionsCheck()}
+
 	for endpoint, cfg := range hcConfig.HealthChecks {
 		externalService, err := findService(endpoint, services)
 		if err != nil {
-			return nil, err
+			return Checks{}, err
 		}
 
 		c := fthealth.Check{
@@ -77,9 +108,40 @@ func NewService(appSystemCode string, appName string,
 			TechnicalSummary: fmt.Sprintf(cfg.TechnicalSummary, endpoint),
 			Checker:          externalServiceChecker(externalService, cfg.CheckerName),
 		}
-		hc.healthChecks = append(hc.healthChecks, c)
+		checks = append(checks, c)
+	}
+
+	sorted := append([]*breaker.Breaker{}, breakers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+	for _, b := range sorted {
+		checks = append(checks, circuitBreakerCheck(b))
+	}
+
+	return Checks{checks: checks}, nil
+}
+
+// Publish replaces the current checks with the prepared ones.
+func (s *Service) Publish(checks Checks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthChecks = checks.checks
+}
+
+func circuitBreakerCheck(b *breaker.Breaker) fthealth.Check {
+	return fthealth.Check{
+		BusinessImpact:   "Requests depending on " + b.Name() + " fail fast without reaching it",
+		Name:             "Circuit breaker for " + b.Name(),
+		PanicGuide:       "https://runbooks.ftops.tech/draft-content-suggestions",
+		Severity:         2,
+		TechnicalSummary: "The circuit breaker opens after repeated failures calling " + b.Name() + ". Check the health of the dependency itself",
+		Checker: func() (string, error) {
+			state := b.State()
+			if state != breaker.Closed {
+				return fmt.Sprintf("circuit is %s", state), fmt.Errorf("circuit breaker for %s is %s", b.Name(), state)
+			}
+			return "circuit is closed", nil
+		},
 	}
-	return hc, nil
 }
 
 func findService(endpoint string, services []ExternalService) (ExternalService, error) {
@@ -102,6 +164,9 @@ func externalServiceChecker(s ExternalService, serviceName string) func() (strin
 }
 
 func (s *Service) Health() fthealth.HC {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return &fthealth.TimedHealthCheck{
 		HealthCheck: fthealth.HealthCheck{
 			SystemCode:  s.config.appSystemCode,
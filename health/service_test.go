@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
 	"github.com/Financial-Times/draft-content-suggestions/config"
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
 	logger "github.com/Financial-Times/go-logger/v2"
 
+	metrics "github.com/rcrowley/go-metrics"
 	logrus "github.com/sirupsen/logrus"
 	logTest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
@@ -27,7 +32,7 @@ func TestHealthService_HealthSuccess(t *testing.T) {
 	contentAPI.On("IsGTG", context.Background()).Return("good to go here!", nil)
 	contentAPI.On("Endpoint").Return("test")
 
-	healthService, err := NewService("", "", "", contentAPI, umbrellaAPI, &config.Config{}, []ExternalService{}, log)
+	healthService, err := NewService("", "", "", contentAPI, umbrellaAPI, &config.Config{}, []ExternalService{}, nil, log)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -48,7 +53,7 @@ func TestHealthService_HealthPartialFailure(t *testing.T) {
 	contentAPI.On("IsGTG", context.Background()).Return("good to go here!", nil)
 	contentAPI.On("Endpoint").Return("test")
 
-	healthService, err := NewService("", "", "", contentAPI, umbrellaAPI, &config.Config{}, []ExternalService{}, log)
+	healthService, err := NewService("", "", "", contentAPI, umbrellaAPI, &config.Config{}, []ExternalService{}, nil, log)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -71,7 +76,7 @@ func TestHealthService_HealthFullFailure(t *testing.T) {
 	contentAPI.On("IsGTG", context.Background()).Return("", errors.New("dying of boredom"))
 	contentAPI.On("Endpoint").Return("test")
 
-	healthService, err := NewService("", "", "", contentAPI, umbrellaAPI, &config.Config{}, []ExternalService{}, log)
+	healthService, err := NewService("", "", "", contentAPI, umbrellaAPI, &config.Config{}, []ExternalService{}, nil, log)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -86,8 +91,98 @@ func TestHealthService_HealthFullFailure(t *testing.T) {
 	}
 }
 
+func TestHealthService_CircuitBreakerChecks(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	settings := breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute}
+	umbrellaBreaker := breaker.New("umbrella", settings, metrics.NewRegistry())
+	draftContentBreaker := breaker.New("draft-content", settings, metrics.NewRegistry())
+
+	resp, err := breaker.NewClient(http.DefaultClient, umbrellaBreaker).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	healthService, err := NewService("", "", "", new(ContentAPI), new(UmbrellaAPI), &config.Config{}, []ExternalService{},
+		[]*breaker.Breaker{umbrellaBreaker, draftContentBreaker}, log)
+	assert.NoError(t, err)
+
+	checks := healthService.healthChecks[2:]
+	if assert.Len(t, checks, 2) {
+		assert.Equal(t, "Circuit breaker for draft-content", checks[0].Name)
+		_, err = checks[0].Checker()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Circuit breaker for umbrella", checks[1].Name)
+		output, err := checks[1].Checker()
+		assert.EqualError(t, err, "circuit breaker for umbrella is open")
+		assert.Equal(t, "circuit is open", output)
+	}
+}
+
+func TestHealthService_Reload(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	article := &externalService{endpoint: "http://upp-article-validator:8080"}
+	articleConfig := &config.Config{HealthChecks: map[string]config.HealthCheckConfig{
+		article.endpoint: {ID: "check-article", Name: "Check upp-article-validator service", TechnicalSummary: "Not available at %v"},
+	}}
+
+	healthService, err := NewService("", "", "", new(ContentAPI), new(UmbrellaAPI), &config.Config{}, []ExternalService{}, nil, log)
+	assert.NoError(t, err)
+	assert.Len(t, healthService.healthChecks, 2)
+
+	err = healthService.Reload(articleConfig, []ExternalService{article}, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, healthService.healthChecks, 3) {
+		assert.Equal(t, "check-article", healthService.healthChecks[2].ID)
+		assert.Equal(t, "Not available at http://upp-article-validator:8080", healthService.healthChecks[2].TechnicalSummary)
+	}
+
+	orphanConfig := &config.Config{HealthChecks: map[string]config.HealthCheckConfig{
+		"http://upp-live-blog-post-validator:8080": {ID: "check-live-blog-post"},
+	}}
+	err = healthService.Reload(orphanConfig, []ExternalService{article}, nil)
+	assert.EqualError(t, err, "unable to find service with endpoint http://upp-live-blog-post-validator:8080")
+	assert.Len(t, healthService.healthChecks, 3, "a rejected reload should keep the current checks")
+}
+
+func TestHealthService_PrepareOnlyReplacesTheChecksOncePublished(t *testing.T) {
+	log := logger.NewUPPLogger("Test", "PANIC")
+	article := &externalService{endpoint: "http://upp-article-validator:8080"}
+	articleConfig := &config.Config{HealthChecks: map[string]config.HealthCheckConfig{
+		article.endpoint: {ID: "check-article", TechnicalSummary: "Not available at %v"},
+	}}
+
+	healthService, err := NewService("", "", "", new(ContentAPI), new(UmbrellaAPI), &config.Config{}, []ExternalService{}, nil, log)
+	assert.NoError(t, err)
+
+	checks, err := healthService.Prepare(articleConfig, []ExternalService{article}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, healthService.healthChecks, 2, "prepared checks should not be published yet")
+
+	healthService.Publish(checks)
+	if assert.Len(t, healthService.healthChecks, 3) {
+		assert.Equal(t, "check-article", healthService.healthChecks[2].ID)
+	}
+}
+
 // Mocks
 
+type externalService struct {
+	endpoint string
+}
+
+func (s *externalService) Endpoint() string {
+	return s.endpoint
+}
+
+func (s *externalService) GTG() error {
+	return nil
+}
+
 // UmbrellaAPI is an autogenerated mock type for the UmbrellaAPI type
 type UmbrellaAPI struct {
 	mock.Mock
@@ -108,15 +203,15 @@ func (_m *UmbrellaAPI) Endpoint() string {
 }
 
 // FetchSuggestions provides a mock function with given fields: ctx, content
-func (_m *UmbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) ([]byte, error) {
+func (_m *UmbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (*suggestions.SuggestionsResponse, error) {
 	ret := _m.Called(ctx, content)
 
-	var r0 []byte
-	if rf, ok := ret.Get(0).(func(context.Context, []byte) []byte); ok {
+	var r0 *suggestions.SuggestionsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *suggestions.SuggestionsResponse); ok {
 		r0 = rf(ctx, content)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*suggestions.SuggestionsResponse)
 		}
 	}
 
@@ -170,6 +265,20 @@ type ContentAPI struct {
 	mock.Mock
 }
 
+// ContentTypes provides a mock function with given fields:
+func (_m *ContentAPI) ContentTypes() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]string)
+	}
+
+	return r0
+}
+
 // Endpoint provides a mock function with given fields:
 func (_m *ContentAPI) Endpoint() string {
 	ret := _m.Called()
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
+
+	"github.com/Financial-Times/draft-content-suggestions/jobs"
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+)
+
+const (
+	asyncQueryParam = "async"
+	jobsPath        = "/drafts/content/suggestions/jobs/"
+	locationHeader  = "Location"
+)
+
+// isAsync tells whether the request asks for its suggestions to be fetched by a background job.
+func isAsync(request *http.Request) (bool, error) {
+	value := request.URL.Query().Get(asyncQueryParam)
+	if value == "" {
+		return false, nil
+	}
+
+	async, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for %s query parameter", value, asyncQueryParam)
+	}
+	return async, nil
+}
+
+// submitSuggestionsJob queues the validation of the content and the fetching of its suggestions as a job,
+// and responds with where the job can be polled.
+func (rh *requestHandler) submitSuggestionsJob(writer http.ResponseWriter, request *http.Request, body []byte, uuid string, filter suggestions.Filter, log *logger.LogEntry) {
+	contentType := request.Header.Get(contentTypeHeader)
+	tid := transactionID(writer, request)
+
+	job, err := rh.jobPool.Submit(NewContextFromRequest(request), func(ctx context.Context) (json.RawMessage, json.RawMessage) {
+		resp, prob := rh.fetchContentSuggestions(ctx, body, uuid, contentType, log)
+		if prob != nil {
+			failure, _ := json.Marshal(rh.problemBody(prob, tid))
+			return nil, failure
+		}
+
+		var result bytes.Buffer
+		if err := filter.Apply(resp).Encode(&result); err != nil {
+			failure, _ := json.Marshal(rh.problemBody(newProblem(problemInternal, "Failed encoding the suggestions"), tid))
+			return nil, failure
+		}
+		return result.Bytes(), nil
+	})
+	if errors.Is(err, jobs.ErrQueueFull) {
+		msg := "Too many suggestions jobs are waiting, retry later"
+		log.WithError(err).Warn(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemJobQueueFull, msg))
+		return
+	}
+	if err != nil {
+		msg := "Failed creating the suggestions job"
+		log.WithError(err).Error(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInternal, msg))
+		return
+	}
+	log.WithField("job", job.ID).Info("Suggestions job submitted")
+
+	writer.Header().Set(locationHeader, jobsPath+job.ID)
+	writeJob(writer, http.StatusAccepted, job, log)
+}
+
+// suggestionsJobRequest returns the status of a suggestions job, along with its result or error once it is done.
+func (rh *requestHandler) suggestionsJobRequest(writer http.ResponseWriter, request *http.Request) {
+	id := mux.Vars(request)["id"]
+	log := rh.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(request)).WithField("job", id)
+
+	job, err := rh.jobPool.Get(request.Context(), id)
+	if errors.Is(err, jobs.ErrNotFound) {
+		msg := "No suggestions job for this id, or it has expired"
+		log.Info(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemJobNotFound, msg))
+		return
+	}
+	if err != nil {
+		msg := "Failed retrieving the suggestions job"
+		log.WithError(err).Error(msg)
+		_ = rh.writeProblem(writer, request, newProblem(problemInternal, msg))
+		return
+	}
+
+	writeJob(writer, http.StatusOK, job, log)
+}
+
+func writeJob(writer http.ResponseWriter, status int, job jobs.Job, log *logger.LogEntry) {
+	writer.Header().Set(contentTypeHeader, "application/json")
+	// the job changes as it runs, so clients polling it should always revalidate it
+	writer.Header().Set(cacheControlHeader, "private, no-cache")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(&job); err != nil {
+		log.WithError(err).Error("Failed responding to suggestions job request")
+	}
+}
@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// ErrQueueFull is returned when a job is submitted while all the workers are busy and the queue is full.
+var ErrQueueFull = errors.New("job queue is full")
+
+// Work is what a job runs. It returns either the encoded result, or the encoded error of a failed job.
+type Work func(ctx context.Context) (result json.RawMessage, failure json.RawMessage)
+
+// Settings of a Pool.
+type Settings struct {
+	// Workers is the number of jobs run concurrently.
+	Workers int
+	// QueueSize is the number of jobs waiting for a worker, further jobs are rejected.
+	QueueSize int
+	// TTL is how long a job is kept for once it is done.
+	TTL time.Duration
+	// Timeout is how long the work of a job can take, zero leaves it unbounded.
+	Timeout time.Duration
+}
+
+// Pool runs the submitted jobs on a bounded number of workers, and records their state in a Store.
+type Pool struct {
+	store    Store
+	ttl      time.Duration
+	timeout  time.Duration
+	workers  int
+	queue    chan queuedJob
+	now      func() time.Time
+	rejected metrics.Counter
+	failed   metrics.Counter
+}
+
+type queuedJob struct {
+	job  Job
+	ctx  context.Context
+	work Work
+}
+
+// NewPool returns a Pool storing its jobs in the store. Its workers are only started by Run.
+// Rejected and failed jobs are counted in the given metrics registry.
+func NewPool(store Store, settings Settings, registry metrics.Registry) *Pool {
+	return &Pool{
+		store:    store,
+		ttl:      settings.TTL,
+		timeout:  settings.Timeout,
+		workers:  settings.Workers,
+		queue:    make(chan queuedJob, settings.QueueSize),
+		now:      time.Now,
+		rejected: metrics.GetOrRegisterCounter("jobs.rejected", registry),
+		failed:   metrics.GetOrRegisterCounter("jobs.failed", registry),
+	}
+}
+
+// Run starts the workers, which stop along with the context, cancelling the jobs they are running.
+func (p *Pool) Run(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.work(ctx)
+	}
+}
+
+// Submit queues the work as a new pending job. The work runs with the values of the context, such as its
+// transaction ID, but is not cancelled along with it.
+func (p *Pool) Submit(ctx context.Context, work Work) (Job, error) {
+	now := p.now()
+	job := Job{
+		ID:        uuid.NewString(),
+		Status:    Pending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := p.store.Put(ctx, job); err != nil {
+		if errors.Is(err, ErrStoreFull) {
+			p.rejected.Inc(1)
+			return Job{}, fmt.Errorf("%w: %w", ErrQueueFull, err)
+		}
+		return Job{}, err
+	}
+
+	select {
+	case p.queue <- queuedJob{job: job, ctx: context.WithoutCancel(ctx), work: work}:
+		return job, nil
+	default:
+		p.rejected.Inc(1)
+		_ = p.store.Delete(ctx, job.ID)
+		return Job{}, ErrQueueFull
+	}
+}
+
+// Get returns the current state of the job.
+func (p *Pool) Get(ctx context.Context, id string) (Job, error) {
+	return p.store.Get(ctx, id)
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case queued := <-p.queue:
+			job := p.update(queued.ctx, queued.job, Running, nil, nil)
+			result, failure := p.run(ctx, queued)
+			if failure != nil {
+				p.failed.Inc(1)
+				p.update(queued.ctx, job, Failed, nil, failure)
+			} else {
+				p.update(queued.ctx, job, Succeeded, result, nil)
+			}
+		}
+	}
+}
+
+// run runs the work of the job within its timeout. The job is also cancelled when the workers stop, while its
+// outcome is still stored with the context it was submitted with.
+func (p *Pool) run(workersCtx context.Context, queued queuedJob) (json.RawMessage, json.RawMessage) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if p.timeout > 0 {
+		ctx, cancel = context.WithTimeout(queued.ctx, p.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(queued.ctx)
+	}
+	defer cancel()
+	stop := context.AfterFunc(workersCtx, cancel)
+	defer stop()
+
+	return queued.work(ctx)
+}
+
+// update stores the job in its new state, which starts its expiry once it is done. A job whose state cannot be
+// stored is left as it was.
+func (p *Pool) update(ctx context.Context, job Job, status Status, result json.RawMessage, failure json.RawMessage) Job {
+	now := p.now()
+	job.Status = status
+	job.UpdatedAt = now
+	if status.Done() {
+		expiresAt := now.Add(p.ttl)
+		job.ExpiresAt = &expiresAt
+	}
+	job.Result = result
+	job.Error = failure
+	_ = p.store.Put(ctx, job)
+	return job
+}
@@ -0,0 +1,159 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type tidKey struct{}
+
+func TestPoolRunsJobs(t *testing.T) {
+	pool := NewPool(NewMemoryStore(100), Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, metrics.NewRegistry())
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	pool.Run(ctx)
+
+	release := make(chan struct{})
+	requestCtx, cancelRequest := context.WithCancel(context.WithValue(context.Background(), tidKey{}, "tid_test"))
+	job, err := pool.Submit(requestCtx, func(ctx context.Context) (json.RawMessage, json.RawMessage) {
+		<-release
+		assert.NoError(t, ctx.Err(), "the job outlives the request")
+		assert.Equal(t, "tid_test", ctx.Value(tidKey{}))
+		return json.RawMessage(`{"suggestions":[]}`), nil
+	})
+	cancelRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, Pending, job.Status)
+
+	assert.Eventually(t, func() bool {
+		job, err = pool.Get(context.Background(), job.ID)
+		return err == nil && job.Status == Running
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		job, err = pool.Get(context.Background(), job.ID)
+		return err == nil && job.Status == Succeeded
+	}, time.Second, time.Millisecond)
+	assert.JSONEq(t, `{"suggestions":[]}`, string(job.Result))
+	assert.Nil(t, job.Error)
+}
+
+func TestPoolRecordsFailedJobs(t *testing.T) {
+	registry := metrics.NewRegistry()
+	pool := NewPool(NewMemoryStore(100), Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, registry)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	pool.Run(ctx)
+
+	job, err := pool.Submit(context.Background(), func(context.Context) (json.RawMessage, json.RawMessage) {
+		return nil, json.RawMessage(`{"status":504}`)
+	})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, err = pool.Get(context.Background(), job.ID)
+		return err == nil && job.Status == Failed
+	}, time.Second, time.Millisecond)
+	assert.JSONEq(t, `{"status":504}`, string(job.Error))
+	assert.Equal(t, int64(1), registry.Get("jobs.failed").(metrics.Counter).Count())
+}
+
+func TestPoolRejectsJobsWhenTheQueueIsFull(t *testing.T) {
+	registry := metrics.NewRegistry()
+	store := NewMemoryStore(100)
+	// the workers are not running, so the queue is never drained
+	pool := NewPool(store, Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, registry)
+	work := func(context.Context) (json.RawMessage, json.RawMessage) { return nil, nil }
+
+	_, err := pool.Submit(context.Background(), work)
+	assert.NoError(t, err)
+	_, err = pool.Submit(context.Background(), work)
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	assert.Len(t, store.(*memoryStore).jobs, 1, "the rejected job is not kept")
+	assert.Equal(t, int64(1), registry.Get("jobs.rejected").(metrics.Counter).Count())
+}
+
+func TestPoolRejectsJobsWhenTheStoreIsFull(t *testing.T) {
+	registry := metrics.NewRegistry()
+	pool := NewPool(NewMemoryStore(1), Settings{Workers: 1, QueueSize: 2, TTL: time.Minute}, registry)
+	work := func(context.Context) (json.RawMessage, json.RawMessage) { return nil, nil }
+
+	_, err := pool.Submit(context.Background(), work)
+	assert.NoError(t, err)
+	_, err = pool.Submit(context.Background(), work)
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.ErrorIs(t, err, ErrStoreFull)
+	assert.Equal(t, int64(1), registry.Get("jobs.rejected").(metrics.Counter).Count())
+}
+
+func TestPoolTimesJobsOut(t *testing.T) {
+	pool := NewPool(NewMemoryStore(100), Settings{Workers: 1, QueueSize: 1, TTL: time.Minute, Timeout: 10 * time.Millisecond}, metrics.NewRegistry())
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	pool.Run(ctx)
+
+	job, err := pool.Submit(context.Background(), func(ctx context.Context) (json.RawMessage, json.RawMessage) {
+		<-ctx.Done()
+		return nil, json.RawMessage(`{"status":504}`)
+	})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, err = pool.Get(context.Background(), job.ID)
+		return err == nil && job.Status == Failed
+	}, time.Second, time.Millisecond)
+	assert.JSONEq(t, `{"status":504}`, string(job.Error))
+}
+
+func TestPoolCancelsRunningJobsWhenStopped(t *testing.T) {
+	pool := NewPool(NewMemoryStore(100), Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, metrics.NewRegistry())
+	ctx, stop := context.WithCancel(context.Background())
+	pool.Run(ctx)
+
+	started := make(chan struct{})
+	job, err := pool.Submit(context.Background(), func(ctx context.Context) (json.RawMessage, json.RawMessage) {
+		close(started)
+		<-ctx.Done()
+		return nil, json.RawMessage(`{"status":503}`)
+	})
+	assert.NoError(t, err)
+
+	<-started
+	stop()
+	assert.Eventually(t, func() bool {
+		job, err = pool.Get(context.Background(), job.ID)
+		return err == nil && job.Status == Failed
+	}, time.Second, time.Millisecond)
+}
+
+func TestPoolOnlyExpiresJobsOnceDone(t *testing.T) {
+	pool := NewPool(NewMemoryStore(100), Settings{Workers: 1, QueueSize: 1, TTL: 10 * time.Millisecond}, metrics.NewRegistry())
+
+	job, err := pool.Submit(context.Background(), func(context.Context) (json.RawMessage, json.RawMessage) {
+		return json.RawMessage(`{"suggestions":[]}`), nil
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, job.ExpiresAt)
+
+	// the job waits in the queue for longer than its TTL, as the workers are not running yet
+	time.Sleep(20 * time.Millisecond)
+	job, err = pool.Get(context.Background(), job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, Pending, job.Status)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	pool.Run(ctx)
+	assert.Eventually(t, func() bool {
+		job, err = pool.Get(context.Background(), job.ID)
+		return err == nil && job.Status == Succeeded
+	}, time.Second, time.Millisecond)
+	assert.NotNil(t, job.ExpiresAt)
+}
@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for the jobs which never existed, or have expired.
+var ErrNotFound = errors.New("job not found")
+
+// ErrStoreFull is returned when a job is stored while the store is full of jobs which are not done.
+var ErrStoreFull = errors.New("job store is full")
+
+// Status of a job.
+type Status string
+
+const (
+	// Pending jobs are queued, waiting for a worker.
+	Pending Status = "pending"
+	// Running jobs are being worked on.
+	Running Status = "running"
+	// Succeeded jobs hold the result of their work.
+	Succeeded Status = "succeeded"
+	// Failed jobs hold the error of their work.
+	Failed Status = "failed"
+)
+
+// Done tells whether the job has either succeeded or failed.
+func (s Status) Done() bool {
+	return s == Succeeded || s == Failed
+}
+
+// Job is the state of a unit of work run in the background. The result and the error are kept encoded,
+// so that jobs can be stored outside of the process.
+type Job struct {
+	ID        string    `json:"id"`
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// ExpiresAt is only set once the job is done, as a job waiting for a worker or running never expires.
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     json.RawMessage `json:"error,omitempty"`
+}
+
+// Store keeps the jobs until they expire.
+type Store interface {
+	// Put creates or replaces the job.
+	Put(ctx context.Context, job Job) error
+	// Get returns the job, or ErrNotFound once it has expired.
+	Get(ctx context.Context, id string) (Job, error)
+	// Delete drops the job before it expires.
+	Delete(ctx context.Context, id string) error
+}
+
+// NewMemoryStore returns a Store keeping at most maxJobs jobs in memory. Expired jobs are dropped in the order
+// they expire as jobs are stored or read. Once the store is full, the done job expiring first makes room for a new
+// one, and Put returns ErrStoreFull when none is done.
+func NewMemoryStore(maxJobs int) Store {
+	return &memoryStore{maxJobs: maxJobs, jobs: map[string]Job{}, now: time.Now}
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	maxJobs  int
+	jobs     map[string]Job
+	expiries expiryHeap
+	now      func() time.Time
+}
+
+func (s *memoryStore) Put(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dropExpired(s.now())
+	if _, found := s.jobs[job.ID]; !found && len(s.jobs) >= s.maxJobs && !s.dropFirstExpiring() {
+		return ErrStoreFull
+	}
+	s.jobs[job.ID] = job
+	if job.ExpiresAt != nil {
+		heap.Push(&s.expiries, expiry{id: job.ID, at: *job.ExpiresAt})
+	}
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dropExpired(s.now())
+	job, found := s.jobs[id]
+	if !found {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.jobs, id)
+	return nil
+}
+
+// dropExpired drops the jobs which have expired at the given time.
+func (s *memoryStore) dropExpired(now time.Time) {
+	for len(s.expiries) > 0 && now.After(s.expiries[0].at) {
+		s.drop(heap.Pop(&s.expiries).(expiry))
+	}
+}
+
+// dropFirstExpiring drops the done job expiring first, telling whether there was one.
+func (s *memoryStore) dropFirstExpiring() bool {
+	for len(s.expiries) > 0 {
+		if s.drop(heap.Pop(&s.expiries).(expiry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// drop drops the job of the expiry, unless the job has been deleted or replaced since, telling whether it did.
+func (s *memoryStore) drop(e expiry) bool {
+	job, found := s.jobs[e.id]
+	if !found || job.ExpiresAt == nil || !job.ExpiresAt.Equal(e.at) {
+		return false
+	}
+	delete(s.jobs, e.id)
+	return true
+}
+
+type expiry struct {
+	id string
+	at time.Time
+}
+
+// expiryHeap orders the expiries of the done jobs, the first to expire at the top.
+type expiryHeap []expiry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *expiryHeap) Push(x any) { *h = append(*h, x.(expiry)) }
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
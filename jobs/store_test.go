@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreExpiresJobs(t *testing.T) {
+	store := NewMemoryStore(100).(*memoryStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	job := Job{ID: "job", Status: Succeeded, ExpiresAt: at(now.Add(time.Minute))}
+	assert.NoError(t, store.Put(context.Background(), job))
+
+	stored, err := store.Get(context.Background(), "job")
+	assert.NoError(t, err)
+	assert.Equal(t, job, stored)
+
+	now = now.Add(2 * time.Minute)
+	_, err = store.Get(context.Background(), "job")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, store.jobs)
+}
+
+func TestMemoryStoreKeepsJobsWithoutExpiry(t *testing.T) {
+	store := NewMemoryStore(100).(*memoryStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "job", Status: Pending}))
+	now = now.Add(24 * time.Hour)
+	_, err := store.Get(context.Background(), "job")
+	assert.NoError(t, err, "a job waiting for a worker does not expire")
+}
+
+func TestMemoryStoreDropsExpiredJobsOnPut(t *testing.T) {
+	store := NewMemoryStore(100).(*memoryStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "expiring", ExpiresAt: at(now.Add(time.Minute))}))
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "new", ExpiresAt: at(now.Add(time.Minute))}))
+
+	assert.Len(t, store.jobs, 1)
+	_, err := store.Get(context.Background(), "new")
+	assert.NoError(t, err)
+}
+
+func TestMemoryStoreExpiresReplacedJobsOnTheirLatestExpiry(t *testing.T) {
+	store := NewMemoryStore(100).(*memoryStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "job", ExpiresAt: at(now.Add(time.Minute))}))
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "job", ExpiresAt: at(now.Add(time.Hour))}))
+	now = now.Add(2 * time.Minute)
+
+	_, err := store.Get(context.Background(), "job")
+	assert.NoError(t, err, "the earlier expiry of a replaced job is ignored")
+}
+
+func TestMemoryStoreMakesRoomByDroppingTheDoneJobExpiringFirst(t *testing.T) {
+	store := NewMemoryStore(3).(*memoryStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "pending", Status: Pending}))
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "later", Status: Succeeded, ExpiresAt: at(now.Add(time.Hour))}))
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "sooner", Status: Failed, ExpiresAt: at(now.Add(time.Minute))}))
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "new", Status: Pending}))
+
+	assert.Len(t, store.jobs, 3)
+	_, err := store.Get(context.Background(), "sooner")
+	assert.ErrorIs(t, err, ErrNotFound)
+	for _, id := range []string{"pending", "later", "new"} {
+		_, err = store.Get(context.Background(), id)
+		assert.NoError(t, err, id)
+	}
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "new", Status: Running}), "replacing a job needs no room")
+}
+
+func TestMemoryStoreRejectsJobsWhenFullOfJobsNotDone(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "pending", Status: Pending}))
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "running", Status: Running}))
+	assert.ErrorIs(t, store.Put(context.Background(), Job{ID: "new", Status: Pending}), ErrStoreFull)
+}
+
+func TestMemoryStoreDelete(t *testing.T) {
+	store := NewMemoryStore(100)
+	assert.NoError(t, store.Put(context.Background(), Job{ID: "job", ExpiresAt: at(time.Now().Add(time.Minute))}))
+	assert.NoError(t, store.Delete(context.Background(), "job"))
+
+	_, err := store.Get(context.Background(), "job")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func at(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Financial-Times/draft-content-suggestions/draft"
+	"github.com/Financial-Times/draft-content-suggestions/jobs"
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+)
+
+const testContentType = "application/vnd.ft-upp-article+json"
+
+func newJobsTestServer(t *testing.T, contentAPI draft.ContentAPI, umbrellaAPI suggestions.UmbrellaAPI, settings jobs.Settings, running bool) *httptest.Server {
+	pool := jobs.NewPool(jobs.NewMemoryStore(100), settings, metrics.NewRegistry())
+	if running {
+		ctx, stop := context.WithCancel(context.Background())
+		t.Cleanup(stop)
+		pool.Run(ctx)
+	}
+	rh := &requestHandler{dca: contentAPI, sua: umbrellaAPI, log: logger.NewUPPLogger("Test", "PANIC"), jobPool: pool}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/suggestions", rh.getDraftSuggestionsForContent).Methods("POST")
+	r.HandleFunc(jobsPath+"{id}", rh.suggestionsJobRequest).Methods("GET")
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAsyncSuggestionsJob(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchValidatedContent", mock.Anything, mock.Anything, "36320eb6-5617-4d12-9750-1907690e74db", testContentType, mock.Anything).Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{
+		{ID: "about", Predicate: "http://www.ft.com/ontology/annotation/about"},
+		{ID: "mentions", Predicate: "http://www.ft.com/ontology/annotation/mentions"},
+	}}, nil)
+
+	ts := newJobsTestServer(t, contentAPI, umbrellaAPI, jobs.Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, true)
+
+	resp, err := http.Post(ts.URL+"/drafts/content/suggestions?async=true&predicate=about", testContentType, bytes.NewReader(content))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var job jobs.Job
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, jobsPath+job.ID, resp.Header.Get("Location"))
+	assert.Equal(t, jobs.Pending, job.Status)
+
+	assert.Eventually(t, func() bool {
+		job = pollJob(t, ts.URL+resp.Header.Get("Location"), http.StatusOK)
+		return job.Status == jobs.Succeeded
+	}, time.Second, 5*time.Millisecond)
+	assert.JSONEq(t, `{"suggestions":[{"id":"about","predicate":"http://www.ft.com/ontology/annotation/about"}]}`, string(job.Result))
+}
+
+func TestAsyncSuggestionsJobFailure(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchValidatedContent", mock.Anything, mock.Anything, "36320eb6-5617-4d12-9750-1907690e74db", testContentType, mock.Anything).Return(([]byte)(nil), context.DeadlineExceeded)
+
+	ts := newJobsTestServer(t, contentAPI, &suggestions.MockSuggestionsUmbrellaAPI{}, jobs.Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, true)
+
+	resp, err := http.Post(ts.URL+"/drafts/content/suggestions?async=1", testContentType, bytes.NewReader(content))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var job jobs.Job
+	assert.Eventually(t, func() bool {
+		job = pollJob(t, ts.URL+resp.Header.Get("Location"), http.StatusOK)
+		return job.Status == jobs.Failed
+	}, time.Second, 5*time.Millisecond)
+
+	var prob problem
+	assert.NoError(t, json.Unmarshal(job.Error, &prob))
+	assert.Equal(t, problemTypePrefix+"dependency-timeout", prob.Type)
+	assert.Equal(t, http.StatusGatewayTimeout, prob.Status)
+}
+
+func TestAsyncSuggestionsJobQueueFull(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	ts := newJobsTestServer(t, &draft.MockDraftContentAPI{}, &suggestions.MockSuggestionsUmbrellaAPI{}, jobs.Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, false)
+
+	expectedStatuses := []int{http.StatusAccepted, http.StatusServiceUnavailable}
+	for _, expectedStatus := range expectedStatuses {
+		resp, err := http.Post(ts.URL+"/drafts/content/suggestions?async=true", testContentType, bytes.NewReader(content))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, expectedStatus, resp.StatusCode)
+	}
+}
+
+func TestAsyncSuggestionsInvalidMode(t *testing.T) {
+	ts := newJobsTestServer(t, &draft.MockDraftContentAPI{}, &suggestions.MockSuggestionsUmbrellaAPI{}, jobs.Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, false)
+
+	resp, err := http.Post(ts.URL+"/drafts/content/suggestions?async=maybe", testContentType, strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var prob problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&prob))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, problemTypePrefix+"invalid-query", prob.Type)
+	assert.Equal(t, `Invalid async mode: invalid value "maybe" for async query parameter`, prob.Detail)
+}
+
+func TestSuggestionsJobNotFound(t *testing.T) {
+	ts := newJobsTestServer(t, &draft.MockDraftContentAPI{}, &suggestions.MockSuggestionsUmbrellaAPI{}, jobs.Settings{Workers: 1, QueueSize: 1, TTL: time.Minute}, false)
+
+	resp, err := http.Get(ts.URL + jobsPath + "9b6b0ab4-8c1c-4d1e-8e2d-4b53c6f6e0a1")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var prob problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&prob))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, problemTypePrefix+"job-not-found", prob.Type)
+}
+
+func pollJob(t *testing.T, url string, expectedStatus int) jobs.Job {
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, expectedStatus, resp.StatusCode)
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	var job jobs.Job
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	return job
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,15 +21,22 @@ import (
 	cli "github.com/jawher/mow.cli"
 	metrics "github.com/rcrowley/go-metrics"
 
+	"github.com/Financial-Times/draft-content-suggestions/breaker"
 	"github.com/Financial-Times/draft-content-suggestions/config"
 	"github.com/Financial-Times/draft-content-suggestions/draft"
 	"github.com/Financial-Times/draft-content-suggestions/health"
+	"github.com/Financial-Times/draft-content-suggestions/jobs"
+	"github.com/Financial-Times/draft-content-suggestions/monitoring"
+	"github.com/Financial-Times/draft-content-suggestions/retry"
 	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+	"github.com/Financial-Times/draft-content-suggestions/tracing"
 )
 
 const (
 	appDescription = "Provides suggestions for draft content."
 	defaultAppName = "draft-content-suggestions"
+	// umbrellaProvider is the name of the suggestion provider calling the Suggestions Umbrella
+	umbrellaProvider = "umbrella"
 )
 
 func main() {
@@ -82,6 +90,30 @@ func main() {
 		Desc:   "Endpoint for Suggestions Umbrella",
 		EnvVar: "SUGGESTIONS_GTG_ENDPOINT",
 	})
+	suggestionProvidersYml := app.String(cli.StringOpt{
+		Name:   "suggestion-providers-yml",
+		Value:  "",
+		Desc:   "Location of the suggestion providers configuration YML file, the Suggestions Umbrella is the only provider when empty",
+		EnvVar: "SUGGESTION_PROVIDERS_YML",
+	})
+	shadowSuggestionsEndpoint := app.String(cli.StringOpt{
+		Name:   "shadow-suggestions-endpoint",
+		Value:  "",
+		Desc:   "Endpoint of a candidate suggestions backend also sent every call, to compare its suggestions with the served ones, empty disables shadowing",
+		EnvVar: "SHADOW_SUGGESTIONS_ENDPOINT",
+	})
+	shadowSuggestionsTimeout := app.String(cli.StringOpt{
+		Name:   "shadow-suggestions-timeout",
+		Value:  "10s",
+		Desc:   "How long a call to the shadowed candidate can take",
+		EnvVar: "SHADOW_SUGGESTIONS_TIMEOUT",
+	})
+	shadowSuggestionsConcurrency := app.Int(cli.IntOpt{
+		Name:   "shadow-suggestions-concurrency",
+		Value:  4,
+		Desc:   "Maximum number of calls to the shadowed candidate in flight, further calls are not shadowed",
+		EnvVar: "SHADOW_SUGGESTIONS_CONCURRENCY",
+	})
 	deliveryBasicAuth := app.String(cli.StringOpt{
 		Name:   "delivery-basic-auth",
 		Value:  "username:password",
@@ -94,6 +126,138 @@ func main() {
 		Desc:   "Location of the Validator configuration YML file.",
 		EnvVar: "VALIDATOR_YML",
 	})
+	validatorYmlPollInterval := app.String(cli.StringOpt{
+		Name:   "validator-yml-poll-interval",
+		Value:  "10s",
+		Desc:   "How often the Validator configuration YML file is checked for changes, 0 only reloads it on SIGHUP",
+		EnvVar: "VALIDATOR_YML_POLL_INTERVAL",
+	})
+	suggestionsCacheSize := app.Int(cli.IntOpt{
+		Name:   "suggestions-cache-size",
+		Value:  1000,
+		Desc:   "Maximum number of suggestions responses kept in the in-memory cache, 0 disables caching",
+		EnvVar: "SUGGESTIONS_CACHE_SIZE",
+	})
+	suggestionsCacheTTL := app.String(cli.StringOpt{
+		Name:   "suggestions-cache-ttl",
+		Value:  "10m",
+		Desc:   "How long a cached suggestions response is served for, e.g. 30s, 10m",
+		EnvVar: "SUGGESTIONS_CACHE_TTL",
+	})
+	retryMaxAttempts := app.Int(cli.IntOpt{
+		Name:   "retry-max-attempts",
+		Value:  3,
+		Desc:   "Maximum number of attempts for each call to a downstream dependency, 1 disables retries",
+		EnvVar: "RETRY_MAX_ATTEMPTS",
+	})
+	retryBaseDelay := app.String(cli.StringOpt{
+		Name:   "retry-base-delay",
+		Value:  "100ms",
+		Desc:   "Base delay of the exponential backoff between attempts",
+		EnvVar: "RETRY_BASE_DELAY",
+	})
+	retryMaxDelay := app.String(cli.StringOpt{
+		Name:   "retry-max-delay",
+		Value:  "2s",
+		Desc:   "Maximum delay between attempts, longer Retry-After values are not honoured",
+		EnvVar: "RETRY_MAX_DELAY",
+	})
+	retryBudgetRatio := app.Float64(cli.Float64Opt{
+		Name:   "retry-budget-ratio",
+		Value:  0.2,
+		Desc:   "Retries allowed per call to each downstream dependency, e.g. 0.2 allows one retry every five calls",
+		EnvVar: "RETRY_BUDGET_RATIO",
+	})
+	breakerFailureThreshold := app.Int(cli.IntOpt{
+		Name:   "circuit-breaker-failure-threshold",
+		Value:  5,
+		Desc:   "Number of consecutive failed calls opening the circuit of a downstream dependency",
+		EnvVar: "CIRCUIT_BREAKER_FAILURE_THRESHOLD",
+	})
+	breakerOpenTimeout := app.String(cli.StringOpt{
+		Name:   "circuit-breaker-open-timeout",
+		Value:  "30s",
+		Desc:   "How long an open circuit fails fast before letting a trial call through",
+		EnvVar: "CIRCUIT_BREAKER_OPEN_TIMEOUT",
+	})
+	batchMaxItems := app.Int(cli.IntOpt{
+		Name:   "batch-max-items",
+		Value:  50,
+		Desc:   "Maximum number of drafts accepted in a single batch suggestions request",
+		EnvVar: "BATCH_MAX_ITEMS",
+	})
+	batchConcurrency := app.Int(cli.IntOpt{
+		Name:   "batch-concurrency",
+		Value:  8,
+		Desc:   "Maximum number of drafts of a batch suggestions request processed concurrently",
+		EnvVar: "BATCH_CONCURRENCY",
+	})
+	asyncWorkers := app.Int(cli.IntOpt{
+		Name:   "async-workers",
+		Value:  4,
+		Desc:   "Number of suggestions jobs run concurrently",
+		EnvVar: "ASYNC_WORKERS",
+	})
+	asyncQueueSize := app.Int(cli.IntOpt{
+		Name:   "async-queue-size",
+		Value:  100,
+		Desc:   "Maximum number of suggestions jobs waiting for a worker, further jobs are rejected",
+		EnvVar: "ASYNC_QUEUE_SIZE",
+	})
+	asyncMaxJobs := app.Int(cli.IntOpt{
+		Name:   "async-max-jobs",
+		Value:  10000,
+		Desc:   "Maximum number of suggestions jobs kept, the done jobs expiring first making room for new ones",
+		EnvVar: "ASYNC_MAX_JOBS",
+	})
+	asyncJobTTL := app.String(cli.StringOpt{
+		Name:   "async-job-ttl",
+		Value:  "10m",
+		Desc:   "How long a suggestions job and its result are kept for once it is done, e.g. 30s, 10m",
+		EnvVar: "ASYNC_JOB_TTL",
+	})
+	asyncJobTimeout := app.String(cli.StringOpt{
+		Name:   "async-job-timeout",
+		Value:  "2m",
+		Desc:   "How long a suggestions job can take, instead of the timeout of the synchronous calls, e.g. 30s, 2m",
+		EnvVar: "ASYNC_JOB_TIMEOUT",
+	})
+	streamPollInterval := app.String(cli.StringOpt{
+		Name:   "stream-poll-interval",
+		Value:  "30s",
+		Desc:   "How often the drafts of the suggestions streams are fetched again, when not notified of their changes",
+		EnvVar: "STREAM_POLL_INTERVAL",
+	})
+	streamHeartbeatInterval := app.String(cli.StringOpt{
+		Name:   "stream-heartbeat-interval",
+		Value:  "15s",
+		Desc:   "How often a heartbeat event is sent to the clients of the suggestions streams",
+		EnvVar: "STREAM_HEARTBEAT_INTERVAL",
+	})
+	legacyErrorResponses := app.Bool(cli.BoolOpt{
+		Name:   "legacy-error-responses",
+		Value:  false,
+		Desc:   "Respond to errors with {\"message\": ...} instead of RFC 7807 problem details",
+		EnvVar: "LEGACY_ERROR_RESPONSES",
+	})
+	tracingExporter := app.String(cli.StringOpt{
+		Name:   "tracing-exporter",
+		Value:  tracing.ExporterNone,
+		Desc:   "Where OpenTelemetry spans are exported to: none, otlp or stdout",
+		EnvVar: "TRACING_EXPORTER",
+	})
+	tracingOTLPEndpoint := app.String(cli.StringOpt{
+		Name:   "tracing-otlp-endpoint",
+		Value:  "",
+		Desc:   "host:port of the OTLP/HTTP collector, the OTEL_EXPORTER_OTLP_* variables apply when empty",
+		EnvVar: "TRACING_OTLP_ENDPOINT",
+	})
+	tracingSampleRatio := app.Float64(cli.Float64Opt{
+		Name:   "tracing-sample-ratio",
+		Value:  1,
+		Desc:   "Ratio of the traces started by the service which are recorded",
+		EnvVar: "TRACING_SAMPLE_RATIO",
+	})
 	logLevel := app.String(cli.StringOpt{
 		Name:   "log-level",
 		Value:  "info",
@@ -114,8 +278,9 @@ func main() {
 			log.WithError(err).Error("Error creating healthchecks HTTP client, exiting ...")
 			return
 		}
+		// the calls to the dependencies are bounded by their own timeout, which the suggestions jobs extend
 		loggingCl, err := fthttp.NewClient(
-			fthttp.WithTimeout(10*time.Second),
+			fthttp.WithTimeout(0),
 			fthttp.WithSysInfo("PAC", *appSystemCode),
 			fthttp.WithLogging(log))
 		if err != nil {
@@ -123,15 +288,68 @@ func main() {
 			return
 		}
 
+		shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:  *appSystemCode,
+			Exporter:     *tracingExporter,
+			OTLPEndpoint: *tracingOTLPEndpoint,
+			SampleRatio:  *tracingSampleRatio,
+		})
+		if err != nil {
+			log.WithError(err).Fatal("Unable to set up tracing")
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				log.WithError(err).Error("Could not flush the remaining spans")
+			}
+		}()
+
+		retryPolicy := retry.DefaultPolicy()
+		retryPolicy.MaxAttempts = *retryMaxAttempts
+		retryPolicy.BudgetRatio = *retryBudgetRatio
+		if retryPolicy.BaseDelay, err = time.ParseDuration(*retryBaseDelay); err != nil {
+			log.WithError(err).Fatal("Invalid retry base delay")
+		}
+		if retryPolicy.MaxDelay, err = time.ParseDuration(*retryMaxDelay); err != nil {
+			log.WithError(err).Fatal("Invalid retry max delay")
+		}
+		// validating and suggesting are pure transformations of the payload, so their POSTs can be safely retried
+		transformRetryPolicy := retryPolicy.WithIdempotentMethods(http.MethodPost)
+
+		breakerSettings := breaker.Settings{FailureThreshold: *breakerFailureThreshold}
+		if breakerSettings.OpenTimeout, err = time.ParseDuration(*breakerOpenTimeout); err != nil {
+			log.WithError(err).Fatal("Invalid circuit breaker open timeout")
+		}
+		promMetrics := monitoring.New(metrics.DefaultRegistry)
+		dependencies := newDependencyClients(loggingCl, 10*time.Second, breakerSettings, promMetrics, log)
+		// the validators are the only dependencies changing along with the configuration, so their clients are kept
+		// apart, to drop the ones it no longer refers to
+		validatorClients := newDependencyClients(loggingCl, 10*time.Second, breakerSettings, promMetrics, log)
+
 		validatorConfig, err := config.ReadConfig(*validatorYml)
 		if err != nil {
 			log.WithError(err).Fatal("unable to read r/w YAML configuration")
 		}
+		if err = validatorConfig.Validate(); err != nil {
+			log.WithError(err).Fatal("Invalid r/w YAML configuration")
+		}
+		pollInterval, err := time.ParseDuration(*validatorYmlPollInterval)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid validator configuration poll interval")
+		}
 
-		contentTypeMapping := draft.BuildContentTypeMapping(validatorConfig, loggingCl, log)
+		validatorClientFor := func(dependency string, endpoint string) *http.Client {
+			return validatorClients.client(dependency, endpoint, transformRetryPolicy)
+		}
+		contentTypeMapping, err := draft.BuildContentTypeMapping(validatorConfig, validatorClientFor, log)
+		if err != nil {
+			log.WithError(err).Fatal("Unable to build the validators of the YAML configuration")
+		}
 		resolver := draft.NewContentValidatorResolver(contentTypeMapping)
 
-		contentAPI, err := draft.NewContentAPI(*draftContentEndpoint, *draftContentGtgEndpoint, loggingCl, healthCl, resolver)
+		draftContentCl := dependencies.client("draft-content", *draftContentEndpoint, retryPolicy)
+		contentAPI, err := draft.NewContentAPI(*draftContentEndpoint, *draftContentGtgEndpoint, draftContentCl, healthCl, resolver)
 		if err != nil {
 			log.WithError(err).Error("Draft Content API error, exiting ...")
 			return
@@ -142,21 +360,142 @@ func main() {
 			log.Fatal("error while resolving basic auth")
 		}
 
-		umbrellaAPI, err := suggestions.NewUmbrellaAPI(*suggestionsEndpoint, *suggestionsGtgEndpoint, basicAuthCredentials[0], basicAuthCredentials[1], loggingCl, healthCl)
+		providersConfig := &suggestions.ProvidersConfig{Providers: []suggestions.ProviderConfig{{
+			Name:              umbrellaProvider,
+			Endpoint:          *suggestionsEndpoint,
+			GTGEndpoint:       *suggestionsGtgEndpoint,
+			DeliveryBasicAuth: true,
+		}}}
+		if *suggestionProvidersYml != "" {
+			if providersConfig, err = suggestions.ReadProvidersConfig(*suggestionProvidersYml); err != nil {
+				log.WithError(err).Fatal("Invalid suggestion providers YAML configuration")
+			}
+		}
+		// only the umbrella provider is shadowed, as the candidate replaces it alone and would otherwise be diffed
+		// with the suggestions of the other providers
+		var shadow func(suggestions.UmbrellaAPI) suggestions.UmbrellaAPI
+		if *shadowSuggestionsEndpoint != "" {
+			shadowSettings := suggestions.ShadowSettings{Concurrency: *shadowSuggestionsConcurrency}
+			if shadowSettings.Concurrency < 1 {
+				log.Fatal("The shadowed suggestions concurrency must be at least 1")
+			}
+			if shadowSettings.Timeout, err = time.ParseDuration(*shadowSuggestionsTimeout); err != nil || shadowSettings.Timeout <= 0 {
+				log.WithError(err).Fatal("Invalid shadowed suggestions timeout")
+			}
+			// the candidate is not health checked, nor its circuit breaker, as it does not serve any response
+			candidateCl := dependencies.client("shadow-candidate", *shadowSuggestionsEndpoint, transformRetryPolicy)
+			candidateAPI, err := suggestions.NewUmbrellaAPI(*shadowSuggestionsEndpoint, "", basicAuthCredentials[0], basicAuthCredentials[1], candidateCl, healthCl)
+			if err != nil {
+				log.WithError(err).Error("Shadowed suggestions candidate API error, exiting ...")
+				return
+			}
+			shadow = func(api suggestions.UmbrellaAPI) suggestions.UmbrellaAPI {
+				return suggestions.NewShadowUmbrellaAPI(api, candidateAPI, shadowSettings, metrics.DefaultRegistry, log)
+			}
+			log.Infof("[Startup] Suggestions of the %s provider shadowed to %s", umbrellaProvider, *shadowSuggestionsEndpoint)
+		}
+		providerClientFor := func(provider string, endpoint string) *http.Client {
+			return dependencies.client(provider, endpoint, transformRetryPolicy)
+		}
+		providers, err := newSuggestionProviders(providersConfig, basicAuthCredentials, providerClientFor, healthCl, shadow)
+		if err != nil {
+			log.WithError(err).Error("Suggestions provider API error, exiting ...")
+			return
+		}
+		umbrellaAPI, err := suggestions.NewProviderRegistry(providers)
 		if err != nil {
-			log.WithError(err).Error("Suggestions Umbrella API error, exiting ...")
+			log.WithError(err).Error("Suggestions provider registry error, exiting ...")
 			return
 		}
 
+		if *suggestionsCacheSize > 0 {
+			cacheTTL, err := time.ParseDuration(*suggestionsCacheTTL)
+			if err != nil {
+				log.WithError(err).Fatal("Invalid suggestions cache TTL")
+			}
+			cache := suggestions.NewLRUCache(*suggestionsCacheSize, cacheTTL)
+			umbrellaAPI = suggestions.NewCachedUmbrellaAPI(umbrellaAPI, cache, metrics.DefaultRegistry)
+			log.Infof("[Startup] Suggestions cache enabled, size: %d, TTL: %s", *suggestionsCacheSize, cacheTTL)
+		}
+
+		healthChecked := providersConfig.Dependencies()
+		healthChecked["draft-content"] = *draftContentEndpoint
+		breakersFor := func(cfg *config.Config) []*breaker.Breaker {
+			return append(dependencies.breakersFor(healthChecked), validatorClients.breakersFor(draft.ValidatorDependencies(cfg))...)
+		}
+
 		healthService, err := health.NewService(*appSystemCode, *appName, appDescription,
-			contentAPI, umbrellaAPI, validatorConfig, extractServices(contentTypeMapping), log)
+			contentAPI, umbrellaAPI, validatorConfig, extractServices(contentTypeMapping), breakersFor(validatorConfig), log)
 		if err != nil {
 			log.WithError(err).Fatal("Unable to create health service")
 		}
 
-		serveEndpoints(*port, apiYml, requestHandler{contentAPI, umbrellaAPI, log}, healthService, log)
+		// everything a configuration needs is built before any of it is published, so that a configuration failing
+		// to build leaves the current one in place, and the publishing itself cannot fail halfway through
+		reloadValidators := func(cfg *config.Config) error {
+			mapping, err := draft.BuildContentTypeMapping(cfg, validatorClientFor, log)
+			if err != nil {
+				return err
+			}
+			checks, err := healthService.Prepare(cfg, extractServices(mapping), breakersFor(cfg))
+			if err != nil {
+				return err
+			}
+			resolver.Reload(mapping)
+			healthService.Publish(checks)
+			validatorClients.retain(draft.ValidatorDependencies(cfg))
+			return nil
+		}
+		watcher := config.NewWatcher(*validatorYml, validatorConfig, pollInterval, reloadValidators, log)
+		go watcher.Run(context.Background())
+
+		jobSettings := jobs.Settings{Workers: *asyncWorkers, QueueSize: *asyncQueueSize}
+		if jobSettings.Workers < 1 {
+			log.Fatal("The number of async workers must be at least 1")
+		}
+		if jobSettings.QueueSize < 1 {
+			log.Fatal("The async queue size must be at least 1")
+		}
+		// the jobs waiting for a worker or running always fit in the store, only done jobs make room for new ones
+		if *asyncMaxJobs < jobSettings.Workers+jobSettings.QueueSize {
+			log.Fatal("The maximum number of async jobs must be at least the number of async workers plus the async queue size")
+		}
+		if jobSettings.TTL, err = time.ParseDuration(*asyncJobTTL); err != nil || jobSettings.TTL <= 0 {
+			log.WithError(err).Fatal("Invalid async job TTL")
+		}
+		if jobSettings.Timeout, err = time.ParseDuration(*asyncJobTimeout); err != nil || jobSettings.Timeout <= 0 {
+			log.WithError(err).Fatal("Invalid async job timeout")
+		}
+		jobPool := jobs.NewPool(jobs.NewMemoryStore(*asyncMaxJobs), jobSettings, metrics.DefaultRegistry)
+		// the workers stop once the server has shut down, cancelling the jobs they are running
+		jobsCtx, stopJobs := context.WithCancel(context.Background())
+		defer stopJobs()
+		jobPool.Run(jobsCtx)
+
+		if *batchConcurrency < 1 {
+			log.Fatal("The batch concurrency must be at least 1")
+		}
+		if *batchMaxItems < 1 {
+			log.Fatal("The maximum number of batch items must be at least 1")
+		}
+		rh := &requestHandler{dca: contentAPI, sua: umbrellaAPI, log: log, legacyErrors: *legacyErrorResponses, jobPool: jobPool}
+		bh := &batchHandler{rh: rh, concurrency: *batchConcurrency, maxItems: *batchMaxItems}
+
+		pollEvery, err := time.ParseDuration(*streamPollInterval)
+		if err != nil || pollEvery <= 0 {
+			log.WithError(err).Fatal("Invalid suggestions stream poll interval")
+		}
+		heartbeatEvery, err := time.ParseDuration(*streamHeartbeatInterval)
+		if err != nil || heartbeatEvery <= 0 {
+			log.WithError(err).Fatal("Invalid suggestions stream heartbeat interval")
+		}
+		sh := newStreamHandler(rh, pollEvery, heartbeatEvery)
+
+		serveEndpoints(*port, apiYml, rh, bh, sh, healthService, promMetrics, log)
 	}
 
+	app.Command("validate-config", "Checks a Validator configuration YML file and exits non-zero on problems", validateConfigCommand)
+
 	err := app.Run(os.Args)
 	if err != nil {
 		log.WithError(err).Errorf("%s could not start!", defaultAppName)
@@ -164,25 +503,79 @@ func main() {
 	}
 }
 
+// newSuggestionProviders creates the API of every configured suggestion provider, each with its own client.
+// The umbrella provider is wrapped by shadow, unless it is nil.
+func newSuggestionProviders(cfg *suggestions.ProvidersConfig, deliveryCredentials []string, clientFor func(provider string, endpoint string) *http.Client, healthCl *http.Client, shadow func(suggestions.UmbrellaAPI) suggestions.UmbrellaAPI) ([]suggestions.Provider, error) {
+	providers := make([]suggestions.Provider, 0, len(cfg.Providers))
+	shadowed := false
+	for _, p := range cfg.Providers {
+		var username, password string
+		if p.DeliveryBasicAuth {
+			username, password = deliveryCredentials[0], deliveryCredentials[1]
+		}
+
+		var api suggestions.UmbrellaAPI
+		if len(p.Variants) == 0 {
+			providerAPI, err := suggestions.NewUmbrellaAPI(p.Endpoint, p.GTGEndpoint, username, password, clientFor(p.Dependency(""), p.Endpoint), healthCl)
+			if err != nil {
+				return nil, fmt.Errorf("suggestion provider %s: %w", p.Name, err)
+			}
+			api = providerAPI
+		} else {
+			variants := make([]suggestions.Variant, 0, len(p.Variants))
+			for _, v := range p.Variants {
+				variantAPI, err := suggestions.NewUmbrellaAPI(v.Endpoint, v.GTGEndpoint, username, password, clientFor(p.Dependency(v.Name), v.Endpoint), healthCl)
+				if err != nil {
+					return nil, fmt.Errorf("variant %s of suggestion provider %s: %w", v.Name, p.Name, err)
+				}
+				variants = append(variants, suggestions.Variant{Name: v.Name, API: variantAPI, Weight: v.Weight})
+			}
+			router, err := suggestions.NewWeightedRouter(p.Name, variants, metrics.DefaultRegistry)
+			if err != nil {
+				return nil, err
+			}
+			api = router
+		}
+
+		if shadow != nil && p.Name == umbrellaProvider {
+			api = shadow(api)
+			shadowed = true
+		}
+		providers = append(providers, suggestions.Provider{Name: p.Name, API: api, Timeout: p.Timeout})
+	}
+	if shadow != nil && !shadowed {
+		return nil, fmt.Errorf("no %s suggestion provider to shadow", umbrellaProvider)
+	}
+
+	return providers, nil
+}
+
 func extractServices(dcm map[string]draft.ContentValidator) []health.ExternalService {
 	result := make([]health.ExternalService, 0, len(dcm))
 
+	// the stages of chained validators are health checked on their own
 	for _, value := range dcm {
-		result = append(result, value)
+		for _, stage := range draft.Stages(value) {
+			result = append(result, stage)
+		}
 	}
 
 	return result
 }
 
-func serveEndpoints(port string, apiYml *string, requestHandler requestHandler, healthService *health.Service, log *logger.UPPLogger) {
+func serveEndpoints(port string, apiYml *string, requestHandler *requestHandler, batchHandler *batchHandler, streamHandler *streamHandler, healthService *health.Service, promMetrics *monitoring.Metrics, log *logger.UPPLogger) {
 	serveMux := http.NewServeMux()
 
-	serveMux.HandleFunc(health.DefaultHealthPath, http.HandlerFunc(fthealth.Handler(healthService.Health())))
+	// the checks change along with the validator configuration, so they are looked up on every request
+	serveMux.HandleFunc(health.DefaultHealthPath, func(w http.ResponseWriter, r *http.Request) {
+		fthealth.Handler(healthService.Health())(w, r)
+	})
 	serveMux.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(healthService.GTG))
 	serveMux.HandleFunc(status.BuildInfoPath, status.BuildInfoHandler)
+	serveMux.Handle(monitoring.Path, promMetrics.Handler())
 
 	if apiYml != nil {
-		apiEndpoint, err := api.NewAPIEndpointForFile(*apiYml)
+		apiEndpoint, err := newAPIEndpoint(*apiYml, requestHandler.dca.ContentTypes)
 		if err != nil {
 			log.WithError(err).WithField("file", apiYml).Warn("Failed to serve the API Endpoint for this service. Please validate the file exists, and that it fits the OpenAPI specification.")
 		} else {
@@ -195,6 +588,15 @@ func serveEndpoints(port string, apiYml *string, requestHandler requestHandler,
 		requestHandler.draftContentSuggestionsRequest).Methods("GET")
 	servicesRouter.HandleFunc("/drafts/content/suggestions",
 		requestHandler.getDraftSuggestionsForContent).Methods("POST")
+	servicesRouter.HandleFunc("/drafts/content/suggestions/batch",
+		batchHandler.batchSuggestionsRequest).Methods("POST")
+	servicesRouter.HandleFunc(jobsPath+"{id}",
+		requestHandler.suggestionsJobRequest).Methods("GET")
+	servicesRouter.HandleFunc("/drafts/content/{uuid}/suggestions/stream",
+		streamHandler.suggestionsStreamRequest).Methods("GET")
+	servicesRouter.HandleFunc("/drafts/content/{uuid}/suggestions/stream/notify",
+		streamHandler.notifyRequest).Methods("POST")
+	servicesRouter.Use(tracing.Middleware, promMetrics.Middleware)
 
 	monitoringRouter := httphandlers.TransactionAwareRequestLoggingHandler(log, servicesRouter)
 	monitoringRouter = httphandlers.HTTPMetricsHandler(metrics.DefaultRegistry, monitoringRouter)
@@ -202,6 +604,8 @@ func serveEndpoints(port string, apiYml *string, requestHandler requestHandler,
 	serveMux.Handle("/", monitoringRouter)
 
 	server := &http.Server{Addr: ":" + port, Handler: serveMux}
+	// streams never end on their own, so they are closed for the server to shut down gracefully
+	server.RegisterOnShutdown(streamHandler.close)
 
 	done := make(chan struct{})
 	go func() {
@@ -20,7 +20,7 @@ const AuthorizationHeader = "Authorization"
 const MockSuggestions = `{
     "suggestions": [
         {
-            "predicate": "http://www.ft.com/ontology/annotation/mentions"
+            "predicate": "http://www.ft.com/ontology/annotation/mentions",
             "id": "http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a",
             "apiUrl": "http://api.ft.com/people/6f14ea94-690f-3ed4-98c7-b926683c735a",
             "prefLabel": "Donald Kaberuka",
@@ -28,7 +28,7 @@ const MockSuggestions = `{
             "isFTAuthor": false
         },
         {
-            "predicate": "http://www.ft.com/ontology/annotation/mentions"
+            "predicate": "http://www.ft.com/ontology/annotation/mentions",
             "id": "http://www.ft.com/thing/9a5e3b4a-55da-498c-816f-9c534e1392bd",
             "apiUrl": "http://api.ft.com/people/9a5e3b4a-55da-498c-816f-9c534e1392bd",
             "prefLabel": "Lawrence Summers",
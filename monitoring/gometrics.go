@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// goMetricsCollector exposes the metrics of a go-metrics registry, such as the cache and circuit breaker ones,
+// converting them on every scrape. Timers are exposed as summaries in seconds, meters as counters of their events.
+type goMetricsCollector struct {
+	registry gometrics.Registry
+}
+
+func newGoMetricsCollector(registry gometrics.Registry) prometheus.Collector {
+	return &goMetricsCollector{registry}
+}
+
+// Describe sends no descriptions, making the collector unchecked, as the go-metrics are only known once registered.
+func (c *goMetricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// collisionsDesc counts the go-metrics which are not exposed, as their name only differs from the name of another
+// one by characters Prometheus does not allow, e.g. a.b and a_b, and two metrics of the same name fail the scrape.
+var (
+	collisionsName = prometheus.BuildFQName(namespace, "", "gometrics_name_collisions")
+	collisionsDesc = prometheus.NewDesc(collisionsName, "go-metrics not exposed as their name collides with the one of another go-metric", nil, nil)
+)
+
+func (c *goMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := map[string]interface{}{}
+	var names []string
+	c.registry.Each(func(name string, metric interface{}) {
+		metrics[name] = metric
+		names = append(names, name)
+	})
+	// the names are sorted, so that the same metric is exposed on every scrape when names collide
+	sort.Strings(names)
+
+	exposed := map[string]bool{collisionsName: true}
+	collisions := 0
+	for _, name := range names {
+		fqName := exposedName(name, metrics[name])
+		if fqName == "" {
+			continue
+		}
+		if exposed[fqName] {
+			collisions++
+			continue
+		}
+		exposed[fqName] = true
+		collect(ch, fqName, name, metrics[name])
+	}
+	ch <- prometheus.MustNewConstMetric(collisionsDesc, prometheus.GaugeValue, float64(collisions))
+}
+
+// exposedName returns the Prometheus name of the go-metric, or an empty one for the kinds of metrics not exposed.
+func exposedName(name string, metric interface{}) string {
+	fqName := prometheus.BuildFQName(namespace, "", sanitizeName(name))
+
+	switch metric.(type) {
+	case gometrics.Counter, gometrics.Gauge, gometrics.GaugeFloat64:
+		return fqName
+	case gometrics.Meter:
+		return fqName + "_total"
+	case gometrics.Timer:
+		return fqName + "_seconds"
+	default:
+		return ""
+	}
+}
+
+func collect(ch chan<- prometheus.Metric, fqName string, name string, metric interface{}) {
+	switch m := metric.(type) {
+	case gometrics.Counter:
+		ch <- prometheus.MustNewConstMetric(newDesc(fqName, name), prometheus.CounterValue, float64(m.Count()))
+	case gometrics.Gauge:
+		ch <- prometheus.MustNewConstMetric(newDesc(fqName, name), prometheus.GaugeValue, float64(m.Value()))
+	case gometrics.GaugeFloat64:
+		ch <- prometheus.MustNewConstMetric(newDesc(fqName, name), prometheus.GaugeValue, m.Value())
+	case gometrics.Meter:
+		ch <- prometheus.MustNewConstMetric(newDesc(fqName, name), prometheus.CounterValue, float64(m.Count()))
+	case gometrics.Timer:
+		snapshot := m.Snapshot()
+		quantiles := []float64{0.5, 0.95, 0.99}
+		values := snapshot.Percentiles(quantiles)
+		summary := make(map[float64]float64, len(quantiles))
+		for i, q := range quantiles {
+			summary[q] = values[i] / 1e9
+		}
+		ch <- prometheus.MustNewConstSummary(newDesc(fqName, name), uint64(snapshot.Count()),
+			float64(snapshot.Sum())/1e9, summary)
+	}
+}
+
+func newDesc(fqName string, originalName string) *prometheus.Desc {
+	return prometheus.NewDesc(fqName, "go-metrics "+originalName, nil, nil)
+}
+
+func sanitizeName(name string) string {
+	return strings.ToLower(invalidNameChars.ReplaceAllString(name, "_"))
+}
@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// Path is where the metrics are exposed in the Prometheus text format.
+const Path = "/metrics"
+
+const namespace = "draft_content_suggestions"
+
+// Metrics records the latency of the requests served and of the calls made to the downstream dependencies,
+// and exposes them along with the go-metrics registered by the service.
+type Metrics struct {
+	registry           *prometheus.Registry
+	requestDuration    *prometheus.HistogramVec
+	dependencyDuration *prometheus.HistogramVec
+	dependencyErrors   *prometheus.CounterVec
+}
+
+// New returns the Metrics of the service, bridging the go-metrics registry into the Prometheus output.
+func New(goMetrics gometrics.Registry) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of the requests served, by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		dependencyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "dependency_request_duration_seconds",
+			Help:      "Duration of every call to a downstream dependency, by status or error.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"dependency", "method", "status"}),
+		dependencyErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dependency_request_errors_total",
+			Help:      "Calls to a downstream dependency which failed with a transport error or a 5xx response.",
+		}, []string{"dependency"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.dependencyDuration,
+		m.dependencyErrors,
+		newGoMetricsCollector(goMetrics),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the duration of the requests matched by a gorilla/mux route, labelled by its path template.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		m.requestDuration.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Observe(time.Since(start).Seconds())
+	})
+}
+
+// NewClient returns a copy of the base client whose calls to the dependency are recorded.
+func (m *Metrics) NewClient(base *http.Client, dependency string) *http.Client {
+	client := *base
+	client.Transport = m.Transport(base.Transport, dependency)
+	return &client
+}
+
+// Transport wraps the next http.RoundTripper so that the duration and outcome of its calls are recorded.
+func (m *Metrics) Transport(next http.RoundTripper, dependency string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		elapsed := time.Since(start).Seconds()
+
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		m.dependencyDuration.WithLabelValues(dependency, req.Method, status).Observe(elapsed)
+		if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+			m.dependencyErrors.WithLabelValues(dependency).Inc()
+		}
+
+		return resp, err
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer, e.g. to flush streamed responses.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
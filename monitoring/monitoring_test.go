@@ -0,0 +1,107 @@
+package monitoring
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	gometrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddlewareRecordsRouteAndStatus(t *testing.T) {
+	m := New(gometrics.NewRegistry())
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	r.Use(m.Middleware)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/drafts/content/6f14ea94-690f-3ed4-98c7-b926683c735a/suggestions", nil))
+
+	assert.Contains(t, scrape(t, m),
+		`draft_content_suggestions_http_request_duration_seconds_count{method="GET",route="/drafts/content/{uuid}/suggestions",status="404"} 1`)
+}
+
+func TestMiddlewareKeepsResponsesFlushable(t *testing.T) {
+	m := New(gometrics.NewRegistry())
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions/stream", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}).Methods("GET")
+	r.Use(m.Middleware)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/drafts/content/6f14ea94-690f-3ed4-98c7-b926683c735a/suggestions/stream", nil))
+	assert.True(t, rec.Flushed)
+}
+
+func TestTransportRecordsDependencyCalls(t *testing.T) {
+	m := New(gometrics.NewRegistry())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := m.NewClient(&http.Client{Timeout: time.Second}, "application/vnd.ft-upp-article+json")
+	for _, path := range []string{"/", "/fail"} {
+		resp, err := client.Get(server.URL + path)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://localhost:1")
+	assert.Error(t, err)
+
+	output := scrape(t, m)
+	for _, status := range []string{"200", "502", "error"} {
+		assert.Contains(t, output,
+			`draft_content_suggestions_dependency_request_duration_seconds_count{dependency="application/vnd.ft-upp-article+json",method="GET",status="`+status+`"} 1`)
+	}
+	assert.Contains(t, output, `draft_content_suggestions_dependency_request_errors_total{dependency="application/vnd.ft-upp-article+json"} 2`)
+}
+
+func TestExposesGoMetrics(t *testing.T) {
+	registry := gometrics.NewRegistry()
+	gometrics.GetOrRegisterCounter("suggestions.cache.hits", registry).Inc(3)
+	gometrics.GetOrRegisterGauge("circuit-breaker.umbrella.state", registry).Update(2)
+	gometrics.GetOrRegisterTimer("GET./drafts/content/suggestions", registry).Update(2 * time.Second)
+
+	output := scrape(t, New(registry))
+
+	assert.Contains(t, output, "draft_content_suggestions_suggestions_cache_hits 3")
+	assert.Contains(t, output, "draft_content_suggestions_circuit_breaker_umbrella_state 2")
+	assert.Contains(t, output, "draft_content_suggestions_get__drafts_content_suggestions_seconds_sum 2")
+	assert.Contains(t, output, "draft_content_suggestions_get__drafts_content_suggestions_seconds_count 1")
+}
+
+func TestExposesOneOfTheGoMetricsWhoseNamesCollide(t *testing.T) {
+	registry := gometrics.NewRegistry()
+	gometrics.GetOrRegisterCounter("jobs.rejected", registry).Inc(1)
+	gometrics.GetOrRegisterCounter("jobs_rejected", registry).Inc(2)
+	gometrics.GetOrRegisterMeter("requests", registry).Mark(3)
+	gometrics.GetOrRegisterCounter("requests.total", registry).Inc(4)
+
+	output := scrape(t, New(registry))
+
+	assert.Contains(t, output, "draft_content_suggestions_jobs_rejected 1")
+	assert.NotContains(t, output, "draft_content_suggestions_jobs_rejected 2")
+	assert.Contains(t, output, "draft_content_suggestions_requests_total 3")
+	assert.Contains(t, output, "draft_content_suggestions_gometrics_name_collisions 2")
+}
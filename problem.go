@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+
+	"github.com/Financial-Times/draft-content-suggestions/draft"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:draft-content-suggestions:problem:"
+)
+
+// problemType is a kind of error response, identified by a stable type URI clients can match on.
+type problemType struct {
+	slug   string
+	title  string
+	status int
+}
+
+var (
+	problemInvalidUUID           = problemType{"invalid-uuid", "Invalid UUID", http.StatusBadRequest}
+	problemInvalidFilter         = problemType{"invalid-filter", "Invalid suggestions filter", http.StatusBadRequest}
+	problemInvalidBody           = problemType{"invalid-body", "Invalid request body", http.StatusBadRequest}
+	problemInvalidBatch          = problemType{"invalid-batch", "Invalid batch request", http.StatusBadRequest}
+	problemValidationFailed      = problemType{"validation-failed", "Draft content failed validation", http.StatusBadRequest}
+	problemUnsupportedMediaType  = problemType{"unsupported-media-type", "Unsupported draft content type", http.StatusUnsupportedMediaType}
+	problemDraftNotFound         = problemType{"draft-not-found", "Draft content not found", http.StatusNotFound}
+	problemDraftNotMappable      = problemType{"draft-not-mappable", "Draft content cannot be mapped", http.StatusUnprocessableEntity}
+	problemDraftContentFailed    = problemType{"draft-content-failed", "Draft content retrieval failed", http.StatusInternalServerError}
+	problemDependencyFailed      = problemType{"dependency-failed", "Dependency failed", http.StatusBadGateway}
+	problemDependencyUnavailable = problemType{"dependency-unavailable", "Dependency temporarily unavailable", http.StatusServiceUnavailable}
+	problemDependencyTimeout     = problemType{"dependency-timeout", "Dependency timed out", http.StatusGatewayTimeout}
+	problemInvalidQuery          = problemType{"invalid-query", "Invalid query parameter", http.StatusBadRequest}
+	problemJobNotFound           = problemType{"job-not-found", "Suggestions job not found", http.StatusNotFound}
+	problemJobQueueFull          = problemType{"job-queue-full", "Too many suggestions jobs", http.StatusServiceUnavailable}
+	problemInternal              = problemType{"internal-error", "Internal error", http.StatusInternalServerError}
+)
+
+// problem is an RFC 7807 problem details error response.
+type problem struct {
+	Type          string          `json:"type"`
+	Title         string          `json:"title"`
+	Status        int             `json:"status"`
+	Detail        string          `json:"detail,omitempty"`
+	TransactionID string          `json:"transactionId,omitempty"`
+	Validator     json.RawMessage `json:"validator,omitempty"`
+	// ValidatorStage names the failing stage of a chained validator.
+	ValidatorStage string `json:"validatorStage,omitempty"`
+	// Errors lists the problems the validator found with specific fields of the draft.
+	Errors []draft.FieldError `json:"errors,omitempty"`
+}
+
+func newProblem(pt problemType, detail string) *problem {
+	return &problem{
+		Type:   problemTypePrefix + pt.slug,
+		Title:  pt.title,
+		Status: pt.status,
+		Detail: detail,
+	}
+}
+
+// withValidatorError adds the error response of the validator and the field errors it lists to the problem,
+// when the error comes from one.
+func (p *problem) withValidatorError(err error) *problem {
+	var validatorErr draft.ValidatorError
+	if errors.As(err, &validatorErr) {
+		p.Validator = validatorErr.Payload()
+		p.ValidatorStage = validatorErr.Stage()
+		p.Errors = validatorErr.FieldErrors()
+	}
+	return p
+}
+
+// writeProblem writes the problem as problem details, or in the legacy {"message": ...} shape
+// for the clients which still rely on it.
+func (rh *requestHandler) writeProblem(w http.ResponseWriter, r *http.Request, p *problem) error {
+	if rh.legacyErrors {
+		w.Header().Set(contentTypeHeader, "application/json")
+	} else {
+		w.Header().Set(contentTypeHeader, problemContentType)
+	}
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(rh.problemBody(p, transactionID(w, r)))
+}
+
+// problemBody returns the body of the error response of the problem, in the shape the clients expect.
+func (rh *requestHandler) problemBody(p *problem, tid string) interface{} {
+	if rh.legacyErrors {
+		return &message{Message: p.Detail}
+	}
+
+	resp := *p
+	resp.TransactionID = tid
+	return &resp
+}
+
+// transactionID returns the transaction ID of the request, preferring the one the request logging middleware
+// has already echoed in the response, as it generates one for the requests which do not carry any.
+func transactionID(w http.ResponseWriter, r *http.Request) string {
+	if tid := w.Header().Get(tidutils.TransactionIDHeader); tid != "" {
+		return tid
+	}
+	return tidutils.GetTransactionIDFromRequest(r)
+}
+
+type message struct {
+	Message string `json:"message"`
+}
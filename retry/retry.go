@@ -0,0 +1,279 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+)
+
+// budgetCapacity is the maximum number of retries a dependency can save up while it is healthy.
+const budgetCapacity = 10
+
+// Policy describes how calls to a single downstream dependency are retried.
+type Policy struct {
+	// MaxAttempts is the total number of attempts per call, including the first one.
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the exponential backoff between attempts.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// BudgetRatio caps the retries to a fraction of the calls made to the dependency,
+	// e.g. 0.2 allows on average one retry for every five calls.
+	BudgetRatio float64
+	// RetryableStatusCodes are the response statuses worth another attempt.
+	RetryableStatusCodes []int
+	// IdempotentMethods are the HTTP methods that are safe to send more than once to the dependency.
+	IdempotentMethods []string
+}
+
+// DefaultPolicy returns a Policy retrying idempotent calls on gateway errors and throttling.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		BudgetRatio: 0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		IdempotentMethods: []string{http.MethodGet, http.MethodHead, http.MethodOptions},
+	}
+}
+
+// WithIdempotentMethods returns a copy of the policy also treating the given methods as idempotent.
+// It is meant for dependencies whose POST endpoints are pure transformations, like the UPP validators.
+func (p Policy) WithIdempotentMethods(methods ...string) Policy {
+	p.IdempotentMethods = append(append([]string{}, p.IdempotentMethods...), methods...)
+	return p
+}
+
+// NewClient returns a copy of the base client retrying its calls to the named dependency as described by the policy.
+// Every client gets its own retry budget.
+func NewClient(base *http.Client, dependency string, policy Policy, log *logger.UPPLogger) *http.Client {
+	client := *base
+	client.Transport = NewTransport(base.Transport, dependency, policy, log)
+	return &client
+}
+
+// NewTransport wraps the next http.RoundTripper with the retry policy of the named dependency.
+func NewTransport(next http.RoundTripper, dependency string, policy Policy, log *logger.UPPLogger) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &transport{
+		next:       next,
+		dependency: dependency,
+		policy:     policy,
+		budget:     &budget{ratio: policy.BudgetRatio, tokens: budgetCapacity},
+		log:        log,
+		jitter:     rand.Int63n,
+	}
+}
+
+type transport struct {
+	next       http.RoundTripper
+	dependency string
+	policy     Policy
+	budget     *budget
+	log        *logger.UPPLogger
+	jitter     func(n int64) int64
+}
+
+// transactionID returns the transaction ID of the call from its context, falling back to its headers for the
+// calls which only carry it there.
+func transactionID(req *http.Request) string {
+	if tid, err := tidutils.GetTransactionIDFromContext(req.Context()); err == nil && tid != "" {
+		return tid
+	}
+	return req.Header.Get(tidutils.TransactionIDHeader)
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.isIdempotent(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	t.budget.deposit()
+	log := t.log.
+		WithTransactionID(transactionID(req)).
+		WithField("dependency", t.dependency).
+		WithField("url", req.URL.String())
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if !t.isRetryable(req.Context(), resp, err) {
+			if attempt > 1 {
+				log.WithField("attempts", attempt).Info("Dependency call completed after retries")
+			}
+			return resp, err
+		}
+
+		attemptLog := log.WithField("attempt", attempt)
+		if err != nil {
+			attemptLog = attemptLog.WithError(err)
+		} else {
+			attemptLog = attemptLog.WithField("status", resp.StatusCode)
+		}
+
+		if attempt >= t.policy.MaxAttempts {
+			attemptLog.Warn("Dependency call failed, no attempts left")
+			return resp, err
+		}
+
+		delay, ok := t.delay(attempt, resp)
+		if !ok {
+			attemptLog.Warn("Dependency call failed, Retry-After exceeds the maximum retry delay")
+			return resp, err
+		}
+		if deadline, hasDeadline := req.Context().Deadline(); hasDeadline && time.Now().Add(delay).After(deadline) {
+			attemptLog.Warn("Dependency call failed, no time left for another attempt")
+			return resp, err
+		}
+		if !t.budget.withdraw() {
+			attemptLog.Warn("Dependency call failed, retry budget exhausted")
+			return resp, err
+		}
+
+		discard(resp)
+		attemptLog.WithField("delay", delay.String()).Warn("Dependency call failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *transport) isIdempotent(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// the body cannot be replayed
+		return false
+	}
+
+	for _, m := range t.policy.IdempotentMethods {
+		if req.Method == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *transport) isRetryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// errors caused by the caller giving up are final
+		return ctx.Err() == nil
+	}
+
+	for _, code := range t.policy.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns how long to wait before the next attempt, honouring the Retry-After header if present.
+// It reports false if the dependency asked us to wait longer than the policy allows.
+func (t *transport) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, found := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); found {
+			return retryAfter, retryAfter <= t.policy.MaxDelay
+		}
+	}
+
+	// exponential backoff with full jitter
+	backoff := float64(t.policy.BaseDelay) * math.Pow(2, float64(attempt-1))
+	backoff = math.Min(backoff, float64(t.policy.MaxDelay))
+	if backoff < 1 {
+		return 0, true
+	}
+
+	return time.Duration(t.jitter(int64(backoff)) + 1), true
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// budget is a token bucket earning a fraction of a retry for every call, and spending a whole one per retry.
+type budget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+func (b *budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(budgetCapacity, b.tokens+b.ratio)
+}
+
+func (b *budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,205 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPolicy() Policy {
+	p := DefaultPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 10 * time.Millisecond
+	return p
+}
+
+func newTestClient(policy Policy) *http.Client {
+	return NewClient(http.DefaultClient, "test", policy, logger.NewUPPLogger("test", "PANIC"))
+}
+
+// newFlakyServer fails the first failures calls with the given status, then succeeds echoing the request body.
+func newFlakyServer(failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	return server, &calls
+}
+
+func TestRetriesIdempotentCallOnRetryableStatus(t *testing.T) {
+	server, calls := newFlakyServer(2, http.StatusBadGateway, nil)
+	defer server.Close()
+
+	resp, err := newTestClient(testPolicy()).Get(server.URL)
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestReturnsLastResponseWhenAttemptsRunOut(t *testing.T) {
+	server, calls := newFlakyServer(5, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	resp, err := newTestClient(testPolicy()).Get(server.URL)
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestDoesNotRetryNonRetryableStatus(t *testing.T) {
+	server, calls := newFlakyServer(1, http.StatusInternalServerError, nil)
+	defer server.Close()
+
+	resp, err := newTestClient(testPolicy()).Get(server.URL)
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestDoesNotRetryNonIdempotentMethod(t *testing.T) {
+	server, calls := newFlakyServer(1, http.StatusBadGateway, nil)
+	defer server.Close()
+
+	resp, err := newTestClient(testPolicy()).Post(server.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetriesPostReplayingTheBody(t *testing.T) {
+	server, calls := newFlakyServer(1, http.StatusBadGateway, nil)
+	defer server.Close()
+
+	client := newTestClient(testPolicy().WithIdempotentMethods(http.MethodPost))
+	resp, err := client.Post(server.URL, "application/json", bytes.NewReader([]byte(`{"uuid":"1"}`)))
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"uuid":"1"}`, string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestHonoursRetryAfter(t *testing.T) {
+	server, calls := newFlakyServer(1, http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"0"}})
+	defer server.Close()
+
+	resp, err := newTestClient(testPolicy()).Get(server.URL)
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestGivesUpWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	server, calls := newFlakyServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"120"}})
+	defer server.Close()
+
+	resp, err := newTestClient(testPolicy()).Get(server.URL)
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRespectsContextDeadline(t *testing.T) {
+	server, calls := newFlakyServer(1, http.StatusBadGateway, nil)
+	defer server.Close()
+
+	policy := testPolicy()
+	policy.BaseDelay = time.Minute
+	policy.MaxDelay = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	client := newTestClient(policy)
+	client.Transport.(*transport).jitter = func(n int64) int64 { return n - 1 }
+	resp, err := client.Do(req)
+
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetryBudget(t *testing.T) {
+	server, calls := newFlakyServer(100, http.StatusBadGateway, nil)
+	defer server.Close()
+
+	policy := testPolicy()
+	policy.MaxAttempts = 2
+	policy.BudgetRatio = 0
+	client := newTestClient(policy)
+
+	for i := 0; i < budgetCapacity+5; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// every call gets its first attempt, but only the saved up retries are spent on top of them
+	assert.Equal(t, int32(2*budgetCapacity+5), atomic.LoadInt32(calls))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value         string
+		expectedDelay time.Duration
+		expectedFound bool
+	}{
+		{value: "", expectedFound: false},
+		{value: "3", expectedDelay: 3 * time.Second, expectedFound: true},
+		{value: "-3", expectedFound: false},
+		{value: "Mon, 01 Jan 2024 12:00:05 GMT", expectedDelay: 5 * time.Second, expectedFound: true},
+		{value: "Mon, 01 Jan 2024 11:00:00 GMT", expectedDelay: 0, expectedFound: true},
+		{value: "soon", expectedFound: false},
+	}
+
+	for _, test := range tests {
+		delay, found := parseRetryAfter(test.value, now)
+		assert.Equal(t, test.expectedFound, found, test.value)
+		assert.Equal(t, test.expectedDelay, delay, test.value)
+	}
+}
+
+func TestTransactionIDPrefersContext(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Set(tidutils.TransactionIDHeader, "tid_header")
+	assert.Equal(t, "tid_header", transactionID(req))
+
+	req = req.WithContext(tidutils.TransactionAwareContext(context.Background(), "tid_context"))
+	assert.Equal(t, "tid_context", transactionID(req))
+}
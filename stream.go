@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
+
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	lastEventIDHeader      = "Last-Event-ID"
+
+	suggestionsEvent = "suggestions"
+	problemEvent     = "problem"
+	heartbeatEvent   = "heartbeat"
+)
+
+// draftNotifier lets the suggestions streams of a draft know that it has changed, so that they fetch it straight
+// away instead of waiting for their next poll.
+type draftNotifier struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func newDraftNotifier() *draftNotifier {
+	return &draftNotifier{subscribers: map[string]map[chan struct{}]struct{}{}}
+}
+
+// subscribe returns the channel notified of the changes of the draft, and the function to stop listening to them.
+func (n *draftNotifier) subscribe(uuid string) (<-chan struct{}, func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// a pending notification is enough for the stream to fetch the latest draft, so further ones are not queued
+	ch := make(chan struct{}, 1)
+	if n.subscribers[uuid] == nil {
+		n.subscribers[uuid] = map[chan struct{}]struct{}{}
+	}
+	n.subscribers[uuid][ch] = struct{}{}
+
+	return ch, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.subscribers[uuid], ch)
+		if len(n.subscribers[uuid]) == 0 {
+			delete(n.subscribers, uuid)
+		}
+	}
+}
+
+// notify wakes up the streams of the draft, and returns how many there are.
+func (n *draftNotifier) notify(uuid string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers[uuid] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return len(n.subscribers[uuid])
+}
+
+// streamHandler pushes the suggestions of a draft as Server-Sent Events, every time they change.
+type streamHandler struct {
+	rh        *requestHandler
+	notifier  *draftNotifier
+	interval  time.Duration
+	heartbeat time.Duration
+
+	// done ends all the streams when the server shuts down
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newStreamHandler(rh *requestHandler, interval time.Duration, heartbeat time.Duration) *streamHandler {
+	return &streamHandler{
+		rh:        rh,
+		notifier:  newDraftNotifier(),
+		interval:  interval,
+		heartbeat: heartbeat,
+		done:      make(chan struct{}),
+	}
+}
+
+// close ends all the streams, which would otherwise keep the server from shutting down.
+func (sh *streamHandler) close() {
+	sh.closeOnce.Do(func() { close(sh.done) })
+}
+
+func (sh *streamHandler) suggestionsStreamRequest(writer http.ResponseWriter, request *http.Request) {
+	uuid := mux.Vars(request)["uuid"]
+	log := sh.rh.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(request)).WithUUID(uuid)
+
+	if err := ValidateUUID(uuid); err != nil {
+		msg := "Invalid UUID"
+		log.WithError(err).Warn(msg)
+		_ = sh.rh.writeProblem(writer, request, newProblem(problemInvalidUUID, msg))
+		return
+	}
+
+	filter, err := suggestions.NewFilterFromQuery(request.URL.Query())
+	if err != nil {
+		msg := "Invalid suggestions filter"
+		log.WithError(err).Warn(msg)
+		_ = sh.rh.writeProblem(writer, request, newProblem(problemInvalidFilter, fmt.Sprintf("%s: %s", msg, err.Error())))
+		return
+	}
+
+	// subscribing first, so that no notification is missed while the draft is first fetched
+	notified, unsubscribe := sh.notifier.subscribe(uuid)
+	defer unsubscribe()
+
+	ctx := NewContextFromRequest(request)
+	payload, prob := sh.fetch(ctx, uuid, filter, log)
+	if prob != nil {
+		// the draft is only streamed once it can be suggested for
+		_ = sh.rh.writeProblem(writer, request, prob)
+		return
+	}
+
+	stream := &eventStream{w: writer, rc: http.NewResponseController(writer)}
+	writer.Header().Set(contentTypeHeader, eventStreamContentType)
+	writer.Header().Set(cacheControlHeader, "no-cache")
+	// keeps the proxies which buffer responses from holding the events back
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+
+	tid := transactionID(writer, request)
+	lastID := request.Header.Get(lastEventIDHeader)
+	var lastProblem string
+	push := func(payload []byte, prob *problem) error {
+		if prob != nil {
+			if prob.Type == lastProblem {
+				return nil
+			}
+			lastProblem = prob.Type
+			body, _ := json.Marshal(sh.rh.problemBody(prob, tid))
+			return stream.send(problemEvent, "", body)
+		}
+
+		lastProblem = ""
+		// the event id only depends on the suggestions, so that revisions of the draft which leave them unchanged
+		// are not pushed
+		id := suggestionsETag(nil, payload)
+		if id == lastID {
+			return nil
+		}
+		lastID = id
+		return stream.send(suggestionsEvent, id, payload)
+	}
+
+	if err := push(payload, nil); err != nil {
+		log.WithError(err).Warn("Failed pushing the draft suggestions")
+		return
+	}
+
+	poll := time.NewTicker(sh.interval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(sh.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			log.Debug("Suggestions stream client disconnected")
+			return
+		case <-sh.done:
+			log.Debug("Suggestions stream closed on shutdown")
+			return
+		case <-heartbeat.C:
+			err = stream.send(heartbeatEvent, "", []byte(time.Now().UTC().Format(time.RFC3339)))
+		case <-poll.C:
+			err = push(sh.fetch(ctx, uuid, filter, log))
+		case <-notified:
+			err = push(sh.fetch(ctx, uuid, filter, log))
+		}
+		if err != nil {
+			log.WithError(err).Warn("Failed pushing the draft suggestions, closing the stream")
+			return
+		}
+	}
+}
+
+// fetch returns the encoded suggestions of the draft, as they are pushed to the stream.
+func (sh *streamHandler) fetch(ctx context.Context, uuid string, filter suggestions.Filter, log *logger.LogEntry) ([]byte, *problem) {
+	ctx, _ = suggestions.ContextWithMetadata(ctx)
+	_, resp, prob := sh.rh.fetchDraftSuggestions(ctx, uuid, log)
+	if prob != nil {
+		return nil, prob
+	}
+
+	var payload bytes.Buffer
+	if err := filter.Apply(resp).Encode(&payload); err != nil {
+		msg := "Failed encoding the suggestions"
+		log.WithError(err).Error(msg)
+		return nil, newProblem(problemInternal, msg)
+	}
+	return bytes.TrimSpace(payload.Bytes()), nil
+}
+
+// notifyRequest makes the suggestions streams of the draft fetch it straight away, e.g. when a post was added to
+// a live blog package.
+func (sh *streamHandler) notifyRequest(writer http.ResponseWriter, request *http.Request) {
+	uuid := mux.Vars(request)["uuid"]
+	log := sh.rh.log.WithTransactionID(tidutils.GetTransactionIDFromRequest(request)).WithUUID(uuid)
+
+	if err := ValidateUUID(uuid); err != nil {
+		msg := "Invalid UUID"
+		log.WithError(err).Warn(msg)
+		_ = sh.rh.writeProblem(writer, request, newProblem(problemInvalidUUID, msg))
+		return
+	}
+
+	streams := sh.notifier.notify(uuid)
+	log.WithField("streams", streams).Debug("Suggestions streams notified")
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// eventStream writes Server-Sent Events, flushing each of them to the client.
+type eventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *eventStream) send(event string, id string, data []byte) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "event: %s\n", event)
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	if _, err := s.w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Financial-Times/draft-content-suggestions/draft"
+	"github.com/Financial-Times/draft-content-suggestions/suggestions"
+)
+
+const streamTestUUID = "36320eb6-5617-4d12-9750-1907690e74db"
+
+type testEvent struct {
+	event string
+	id    string
+	data  string
+}
+
+func newStreamTestServer(t *testing.T, contentAPI draft.ContentAPI, umbrellaAPI suggestions.UmbrellaAPI, heartbeat time.Duration) (*httptest.Server, *streamHandler) {
+	rh := &requestHandler{dca: contentAPI, sua: umbrellaAPI, log: logger.NewUPPLogger("Test", "PANIC")}
+	sh := newStreamHandler(rh, time.Hour, heartbeat)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions/stream", sh.suggestionsStreamRequest).Methods("GET")
+	r.HandleFunc("/drafts/content/{uuid}/suggestions/stream/notify", sh.notifyRequest).Methods("POST")
+	ts := httptest.NewServer(r)
+	t.Cleanup(func() {
+		sh.close()
+		ts.Close()
+	})
+	return ts, sh
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) testEvent {
+	var e testEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return e
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return e
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data += strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func notifyStream(t *testing.T, ts *httptest.Server) {
+	resp, err := http.Post(ts.URL+"/drafts/content/"+streamTestUUID+"/suggestions/stream/notify", "", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestSuggestionsStreamPushesChangedSuggestions(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, streamTestUUID).Return(content, nil)
+	first := &suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{{ID: "first", Predicate: "about"}}}
+	second := &suggestions.SuggestionsResponse{Suggestions: []suggestions.Suggestion{{ID: "first", Predicate: "about"}, {ID: "second", Predicate: "mentions"}}}
+	var fetches atomic.Int32
+	countFetches := func(mock.Arguments) { fetches.Add(1) }
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Run(countFetches).Return(first, nil).Twice()
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Run(countFetches).Return(second, nil)
+
+	ts, sh := newStreamTestServer(t, contentAPI, umbrellaAPI, time.Hour)
+
+	resp, err := http.Get(ts.URL + "/drafts/content/" + streamTestUUID + "/suggestions/stream")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	e := readEvent(t, reader)
+	assert.Equal(t, "suggestions", e.event)
+	assert.NotEmpty(t, e.id)
+	assert.JSONEq(t, `{"suggestions":[{"id":"first","predicate":"about"}]}`, e.data)
+
+	// the first notification leaves the suggestions unchanged, so only the second one is pushed
+	notifyStream(t, ts)
+	assert.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+	notifyStream(t, ts)
+
+	changed := readEvent(t, reader)
+	assert.Equal(t, "suggestions", changed.event)
+	assert.NotEqual(t, e.id, changed.id)
+	assert.JSONEq(t, `{"suggestions":[{"id":"first","predicate":"about"},{"id":"second","predicate":"mentions"}]}`, changed.data)
+
+	resp.Body.Close()
+	assert.Eventually(t, func() bool {
+		sh.notifier.mu.Lock()
+		defer sh.notifier.mu.Unlock()
+		return len(sh.notifier.subscribers) == 0
+	}, time.Second, time.Millisecond, "the stream is torn down once the client disconnects")
+}
+
+func TestSuggestionsStreamSendsHeartbeats(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, streamTestUUID).Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil)
+
+	ts, _ := newStreamTestServer(t, contentAPI, umbrellaAPI, 10*time.Millisecond)
+
+	resp, err := http.Get(ts.URL + "/drafts/content/" + streamTestUUID + "/suggestions/stream")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	assert.Equal(t, "suggestions", readEvent(t, reader).event)
+	assert.Equal(t, "heartbeat", readEvent(t, reader).event)
+}
+
+func TestSuggestionsStreamResumesFromLastEventID(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, streamTestUUID).Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil)
+
+	ts, _ := newStreamTestServer(t, contentAPI, umbrellaAPI, 10*time.Millisecond)
+
+	resp, err := http.Get(ts.URL + "/drafts/content/" + streamTestUUID + "/suggestions/stream")
+	assert.NoError(t, err)
+	first := readEvent(t, bufio.NewReader(resp.Body))
+	resp.Body.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/drafts/content/"+streamTestUUID+"/suggestions/stream", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Last-Event-ID", first.id)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "heartbeat", readEvent(t, bufio.NewReader(resp.Body)).event, "the suggestions the client already has are not pushed again")
+}
+
+func TestSuggestionsStreamOfMissingDraft(t *testing.T) {
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, streamTestUUID).Return(([]byte)(nil), nil)
+
+	ts, _ := newStreamTestServer(t, contentAPI, &suggestions.MockSuggestionsUmbrellaAPI{}, time.Hour)
+
+	resp, err := http.Get(ts.URL + "/drafts/content/" + streamTestUUID + "/suggestions/stream")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, problemContentType, resp.Header.Get("Content-Type"))
+}
+
+func TestSuggestionsStreamClosedOnShutdown(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	contentAPI := &draft.MockDraftContentAPI{}
+	contentAPI.On("FetchDraftContent", mock.Anything, streamTestUUID).Return(content, nil)
+	umbrellaAPI := &suggestions.MockSuggestionsUmbrellaAPI{}
+	umbrellaAPI.On("FetchSuggestions", mock.Anything, content).Return(&suggestions.SuggestionsResponse{}, nil)
+
+	ts, sh := newStreamTestServer(t, contentAPI, umbrellaAPI, time.Hour)
+
+	resp, err := http.Get(ts.URL + "/drafts/content/" + streamTestUUID + "/suggestions/stream")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	readEvent(t, reader)
+
+	sh.close()
+	_, err = reader.ReadString('\n')
+	assert.Error(t, err, "the stream ends")
+}
+
+func TestDraftNotifier(t *testing.T) {
+	n := newDraftNotifier()
+	first, unsubscribeFirst := n.subscribe(streamTestUUID)
+	second, unsubscribeSecond := n.subscribe(streamTestUUID)
+
+	assert.Equal(t, 2, n.notify(streamTestUUID))
+	assert.Equal(t, 2, n.notify(streamTestUUID), "notifications are not queued up")
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 1)
+	assert.Equal(t, 0, n.notify("9b6b0ab4-8c1c-4d1e-8e2d-4b53c6f6e0a1"))
+
+	unsubscribeFirst()
+	unsubscribeSecond()
+	assert.Empty(t, n.subscribers)
+}
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/Financial-Times/draft-content-suggestions/endpointessentials"
+	"github.com/Financial-Times/draft-content-suggestions/tracing"
 )
 
 const (
@@ -31,13 +31,27 @@ func NewUmbrellaAPI(endpoint string, gtgEndpoint string, username string, passwo
 type UmbrellaAPI interface {
 	// FetchSuggestions
 	// Makes a API request to Suggestions Umbrella and returns the
-	// []byte body
-	FetchSuggestions(ctx context.Context, content []byte) (suggestion []byte, err error)
+	// decoded suggestions
+	FetchSuggestions(ctx context.Context, content []byte) (suggestion *SuggestionsResponse, err error)
 
 	// Embedded Endpoint interface, check its godoc
 	endpointessentials.Endpoint
 }
 
+// UmbrellaError is returned when Suggestions Umbrella responds with another status than 200 OK.
+type UmbrellaError struct {
+	httpStatus int
+	msg        string
+}
+
+func (e UmbrellaError) Error() string {
+	return e.msg
+}
+
+func (e UmbrellaError) StatusCode() int {
+	return e.httpStatus
+}
+
 type umbrellaAPI struct {
 	endpoint         string
 	gtgEndpoint      string
@@ -47,13 +61,16 @@ type umbrellaAPI struct {
 	healthHTTPClient *http.Client
 }
 
-func (u *umbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (suggestion []byte, err error) {
+func (u *umbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (suggestion *SuggestionsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "suggestions.FetchSuggestions")
+	defer func() { tracing.End(span, err) }()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.endpoint, bytes.NewBuffer(content))
 	if err != nil {
 		return nil, err
 	}
 
-	req.SetBasicAuth(u.username, u.password)
+	u.setBasicAuth(req)
 	req.Header.Set(OriginHeader, Origin)
 
 	res, err := u.httpClient.Do(req)
@@ -63,10 +80,10 @@ func (u *umbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (sug
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("suggestions Umbrella endpoint fail: %s", res.Status)
+		return nil, UmbrellaError{httpStatus: res.StatusCode, msg: fmt.Sprintf("suggestions Umbrella endpoint fail: %s", res.Status)}
 	}
 
-	suggestion, err = io.ReadAll(res.Body)
+	suggestion, err = DecodeSuggestionsResponse(res.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed reading the response body from Suggestions Umbrella endpoint: %w", err)
 	}
@@ -84,7 +101,7 @@ func (u *umbrellaAPI) IsGTG(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("error creating GTG request: %w", err)
 	}
 
-	req.SetBasicAuth(u.username, u.password)
+	u.setBasicAuth(req)
 
 	response, err := u.healthHTTPClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -99,6 +116,13 @@ func (u *umbrellaAPI) IsGTG(ctx context.Context) (string, error) {
 	return "UPP suggestions API is healthy", nil
 }
 
+// setBasicAuth authenticates the request, unless the API was created without credentials.
+func (u *umbrellaAPI) setBasicAuth(req *http.Request) {
+	if u.username != "" {
+		req.SetBasicAuth(u.username, u.password)
+	}
+}
+
 func (u *umbrellaAPI) IsValid() error {
 	return endpointessentials.ValidateEndpoint(u.endpoint)
 }
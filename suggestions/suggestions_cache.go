@@ -0,0 +1,155 @@
+package suggestions
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// CacheEntry is a suggestions response, along with the variants its providers routed it to.
+type CacheEntry struct {
+	Response *SuggestionsResponse
+	// Variants maps the providers to their variants.
+	Variants map[string]string
+}
+
+// Cache stores suggestions responses by key.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+}
+
+// NewLRUCache returns an in-memory Cache holding at most maxEntries responses,
+// each of them for no longer than ttl. The least recently used entry is evicted first.
+func NewLRUCache(maxEntries int, ttl time.Duration) Cache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    list.New(),
+		index:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    *list.List
+	index      map[string]*list.Element
+	now        func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	entry     CacheEntry
+	expiresAt time.Time
+}
+
+func (c *lruCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.index[key]
+	if !found {
+		return CacheEntry{}, false
+	}
+
+	e := el.Value.(*lruEntry)
+	if c.now().After(e.expiresAt) {
+		c.remove(el)
+		return CacheEntry{}, false
+	}
+
+	c.entries.MoveToFront(el)
+	return e.entry, true
+}
+
+func (c *lruCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if el, found := c.index[key]; found {
+		e := el.Value.(*lruEntry)
+		e.entry = entry
+		e.expiresAt = expiresAt
+		c.entries.MoveToFront(el)
+		return
+	}
+
+	c.index[key] = c.entries.PushFront(&lruEntry{key: key, entry: entry, expiresAt: expiresAt})
+	for c.entries.Len() > c.maxEntries {
+		c.remove(c.entries.Back())
+	}
+}
+
+func (c *lruCache) remove(el *list.Element) {
+	c.entries.Remove(el)
+	delete(c.index, el.Value.(*lruEntry).key)
+}
+
+// NewCachedUmbrellaAPI wraps an UmbrellaAPI so that suggestions are looked up in the cache
+// by a hash of the content before calling the Suggestions Umbrella.
+// Cache hits and misses are counted in the given metrics registry. The variants the response was routed to are
+// cached along with it, and restored in the Metadata of the context on every hit, where they are counted as well.
+func NewCachedUmbrellaAPI(api UmbrellaAPI, cache Cache, registry metrics.Registry) UmbrellaAPI {
+	return &cachedUmbrellaAPI{
+		UmbrellaAPI: api,
+		cache:       cache,
+		registry:    registry,
+		hits:        metrics.GetOrRegisterCounter("suggestions.cache.hits", registry),
+		misses:      metrics.GetOrRegisterCounter("suggestions.cache.misses", registry),
+	}
+}
+
+type cachedUmbrellaAPI struct {
+	UmbrellaAPI
+	cache    Cache
+	registry metrics.Registry
+	hits     metrics.Counter
+	misses   metrics.Counter
+}
+
+func (c *cachedUmbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (*SuggestionsResponse, error) {
+	key := contentHash(content)
+	meta := MetadataFromContext(ctx)
+	if meta == nil {
+		// the failed providers are recorded even for the callers which do not read them, to tell partial responses
+		ctx, meta = ContextWithMetadata(ctx)
+	}
+
+	if entry, found := c.cache.Get(key); found {
+		c.hits.Inc(1)
+		meta.SetCacheStatus(CacheHit)
+		for provider, variant := range entry.Variants {
+			meta.SetVariant(provider, variant)
+			variantCounter(provider, variant, c.registry).Inc(1)
+		}
+		return entry.Response, nil
+	}
+
+	c.misses.Inc(1)
+	meta.SetCacheStatus(CacheMiss)
+
+	resp, err := c.UmbrellaAPI.FetchSuggestions(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// partial responses are not cached, so that the failed providers are called again on the next request
+	if len(meta.FailedProviders()) == 0 {
+		c.cache.Set(key, CacheEntry{Response: resp, Variants: meta.variantsByProvider()})
+	}
+	return resp, nil
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,116 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2, time.Minute)
+	first := &SuggestionsResponse{}
+	second := &SuggestionsResponse{}
+	third := &SuggestionsResponse{}
+
+	cache.Set("first", CacheEntry{Response: first})
+	cache.Set("second", CacheEntry{Response: second})
+	_, found := cache.Get("first")
+	assert.True(t, found)
+
+	cache.Set("third", CacheEntry{Response: third})
+
+	_, found = cache.Get("second")
+	assert.False(t, found, "second should have been evicted as the least recently used entry")
+	entry, found := cache.Get("first")
+	assert.True(t, found)
+	assert.Same(t, first, entry.Response)
+	entry, found = cache.Get("third")
+	assert.True(t, found)
+	assert.Same(t, third, entry.Response)
+}
+
+func TestLRUCacheExpiresEntries(t *testing.T) {
+	cache := NewLRUCache(10, time.Minute).(*lruCache)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Set("key", CacheEntry{Response: &SuggestionsResponse{}})
+	_, found := cache.Get("key")
+	assert.True(t, found)
+
+	now = now.Add(2 * time.Minute)
+	_, found = cache.Get("key")
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.entries.Len())
+}
+
+func TestCachedUmbrellaAPI(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	expected := &SuggestionsResponse{Suggestions: []Suggestion{{ID: "id", Predicate: "predicate"}}}
+
+	api := &MockSuggestionsUmbrellaAPI{}
+	api.On("FetchSuggestions", mock.Anything, content).Return(expected, nil).Once()
+
+	registry := metrics.NewRegistry()
+	cachedAPI := NewCachedUmbrellaAPI(api, NewLRUCache(10, time.Minute), registry)
+
+	ctx, meta := ContextWithMetadata(context.Background())
+	resp, err := cachedAPI.FetchSuggestions(ctx, content)
+	assert.NoError(t, err)
+	assert.Same(t, expected, resp)
+	assert.Equal(t, CacheMiss, meta.CacheStatus())
+
+	ctx, meta = ContextWithMetadata(context.Background())
+	resp, err = cachedAPI.FetchSuggestions(ctx, content)
+	assert.NoError(t, err)
+	assert.Same(t, expected, resp)
+	assert.Equal(t, CacheHit, meta.CacheStatus())
+
+	api.AssertExpectations(t)
+	assert.Equal(t, int64(1), registry.Get("suggestions.cache.hits").(metrics.Counter).Count())
+	assert.Equal(t, int64(1), registry.Get("suggestions.cache.misses").(metrics.Counter).Count())
+}
+
+func TestCachedUmbrellaAPIDoesNotCacheErrors(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+
+	api := &MockSuggestionsUmbrellaAPI{}
+	api.On("FetchSuggestions", mock.Anything, content).Return((*SuggestionsResponse)(nil), errors.New("umbrella is down")).Twice()
+
+	cachedAPI := NewCachedUmbrellaAPI(api, NewLRUCache(10, time.Minute), metrics.NewRegistry())
+
+	for i := 0; i < 2; i++ {
+		resp, err := cachedAPI.FetchSuggestions(context.Background(), content)
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	}
+	api.AssertExpectations(t)
+}
+
+func TestCachedUmbrellaAPIDoesNotCachePartialResponses(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	partial := &SuggestionsResponse{Suggestions: []Suggestion{{ID: "id", Predicate: "predicate"}}}
+
+	api := &MockSuggestionsUmbrellaAPI{}
+	api.On("FetchSuggestions", mock.Anything, content).
+		Run(func(args mock.Arguments) {
+			MetadataFromContext(args.Get(0).(context.Context)).AddFailedProvider("extractor")
+		}).
+		Return(partial, nil).Twice()
+
+	cachedAPI := NewCachedUmbrellaAPI(api, NewLRUCache(10, time.Minute), metrics.NewRegistry())
+
+	for i := 0; i < 2; i++ {
+		ctx, meta := ContextWithMetadata(context.Background())
+		resp, err := cachedAPI.FetchSuggestions(ctx, content)
+		assert.NoError(t, err)
+		assert.Same(t, partial, resp)
+		assert.Equal(t, CacheMiss, meta.CacheStatus())
+	}
+	api.AssertExpectations(t)
+}
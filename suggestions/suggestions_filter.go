@@ -0,0 +1,114 @@
+package suggestions
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	PredicateQueryParam = "predicate"
+	TypeQueryParam      = "type"
+
+	excludePrefix = "!"
+)
+
+// Filter narrows a suggestions response down by predicate and concept type.
+// A suggestion is kept when it matches at least one of the included values (if any)
+// and none of the excluded values.
+type Filter struct {
+	IncludePredicates []string
+	ExcludePredicates []string
+	IncludeTypes      []string
+	ExcludeTypes      []string
+}
+
+// NewFilterFromQuery builds a Filter from the repeatable predicate and type query parameters.
+// Values prefixed with "!" exclude matching suggestions, any other value includes them.
+// A value matches either the full URI or its last path segment, e.g. "about" or "Person".
+func NewFilterFromQuery(query url.Values) (Filter, error) {
+	var f Filter
+	var err error
+
+	f.IncludePredicates, f.ExcludePredicates, err = splitFilterValues(PredicateQueryParam, query[PredicateQueryParam])
+	if err != nil {
+		return Filter{}, err
+	}
+	f.IncludeTypes, f.ExcludeTypes, err = splitFilterValues(TypeQueryParam, query[TypeQueryParam])
+	if err != nil {
+		return Filter{}, err
+	}
+
+	return f, nil
+}
+
+func splitFilterValues(param string, values []string) (include []string, exclude []string, err error) {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		excluded := strings.HasPrefix(v, excludePrefix)
+		v = strings.TrimPrefix(v, excludePrefix)
+		if v == "" {
+			return nil, nil, fmt.Errorf("empty value for %s query parameter", param)
+		}
+
+		if excluded {
+			exclude = append(exclude, v)
+		} else {
+			include = append(include, v)
+		}
+	}
+
+	return include, exclude, nil
+}
+
+// IsEmpty reports whether the filter would keep every suggestion.
+func (f Filter) IsEmpty() bool {
+	return len(f.IncludePredicates) == 0 && len(f.ExcludePredicates) == 0 &&
+		len(f.IncludeTypes) == 0 && len(f.ExcludeTypes) == 0
+}
+
+// Apply returns a new response holding only the suggestions accepted by the filter.
+// The given response is left untouched.
+func (f Filter) Apply(resp *SuggestionsResponse) *SuggestionsResponse {
+	if f.IsEmpty() {
+		return resp
+	}
+
+	// the other properties of the response are kept as they are
+	filtered := *resp
+	filtered.Suggestions = make([]Suggestion, 0, len(resp.Suggestions))
+	for _, s := range resp.Suggestions {
+		if f.accepts(s) {
+			filtered.Suggestions = append(filtered.Suggestions, s)
+		}
+	}
+
+	return &filtered
+}
+
+func (f Filter) accepts(s Suggestion) bool {
+	return accepts(s.Predicate, f.IncludePredicates, f.ExcludePredicates) &&
+		accepts(s.Type, f.IncludeTypes, f.ExcludeTypes)
+}
+
+func accepts(uri string, include []string, exclude []string) bool {
+	if len(include) > 0 && !matchesAny(uri, include) {
+		return false
+	}
+
+	return !matchesAny(uri, exclude)
+}
+
+func matchesAny(uri string, values []string) bool {
+	for _, v := range values {
+		if uri == v || strings.EqualFold(lastPathSegment(uri), v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lastPathSegment(uri string) string {
+	return uri[strings.LastIndex(uri, "/")+1:]
+}
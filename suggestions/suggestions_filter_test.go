@@ -0,0 +1,112 @@
+package suggestions
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	predicateAbout    = "http://www.ft.com/ontology/annotation/about"
+	predicateMentions = "http://www.ft.com/ontology/annotation/mentions"
+	predicateAuthor   = "http://www.ft.com/ontology/hasContributor"
+	typePerson        = "http://www.ft.com/ontology/person/Person"
+	typeOrganisation  = "http://www.ft.com/ontology/organisation/Organisation"
+	typeTopic         = "http://www.ft.com/ontology/Topic"
+)
+
+func TestNewFilterFromQuery(t *testing.T) {
+	query := url.Values{
+		PredicateQueryParam: []string{"about", "!" + predicateMentions},
+		TypeQueryParam:      []string{"Person", "!Topic"},
+		"unrelated":         []string{"value"},
+	}
+
+	f, err := NewFilterFromQuery(query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Filter{
+		IncludePredicates: []string{"about"},
+		ExcludePredicates: []string{predicateMentions},
+		IncludeTypes:      []string{"Person"},
+		ExcludeTypes:      []string{"Topic"},
+	}, f)
+}
+
+func TestNewFilterFromQueryEmptyValue(t *testing.T) {
+	for _, query := range []url.Values{
+		{PredicateQueryParam: []string{""}},
+		{TypeQueryParam: []string{"!"}},
+	} {
+		_, err := NewFilterFromQuery(query)
+		assert.Error(t, err)
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	resp := &SuggestionsResponse{Suggestions: []Suggestion{
+		{ID: "1", Predicate: predicateAbout, Type: typePerson},
+		{ID: "2", Predicate: predicateMentions, Type: typeOrganisation},
+		{ID: "3", Predicate: predicateAuthor, Type: typePerson},
+		{ID: "4", Predicate: predicateAbout, Type: typeTopic},
+	}}
+
+	tests := []struct {
+		name        string
+		query       url.Values
+		expectedIDs []string
+	}{
+		{
+			name:        "No filter",
+			query:       url.Values{},
+			expectedIDs: []string{"1", "2", "3", "4"},
+		},
+		{
+			name:        "Include predicates by short name",
+			query:       url.Values{PredicateQueryParam: []string{"about", "hasContributor"}},
+			expectedIDs: []string{"1", "3", "4"},
+		},
+		{
+			name:        "Include predicate by full URI",
+			query:       url.Values{PredicateQueryParam: []string{predicateMentions}},
+			expectedIDs: []string{"2"},
+		},
+		{
+			name:        "Exclude predicate",
+			query:       url.Values{PredicateQueryParam: []string{"!mentions"}},
+			expectedIDs: []string{"1", "3", "4"},
+		},
+		{
+			name:        "Include types case insensitively",
+			query:       url.Values{TypeQueryParam: []string{"person", "organisation"}},
+			expectedIDs: []string{"1", "2", "3"},
+		},
+		{
+			name:        "Include predicate and exclude type",
+			query:       url.Values{PredicateQueryParam: []string{"about"}, TypeQueryParam: []string{"!Topic"}},
+			expectedIDs: []string{"1"},
+		},
+		{
+			name:        "Nothing matches",
+			query:       url.Values{TypeQueryParam: []string{"Location"}},
+			expectedIDs: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := NewFilterFromQuery(test.query)
+			assert.NoError(t, err)
+
+			filtered := f.Apply(resp)
+
+			ids := []string{}
+			for _, s := range filtered.Suggestions {
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+			assert.Len(t, resp.Suggestions, 4, "the original response should be left untouched")
+		})
+	}
+}
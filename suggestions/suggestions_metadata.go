@@ -0,0 +1,122 @@
+package suggestions
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type metadataKey struct{}
+
+// CacheStatus tells whether a suggestions response was served from the cache.
+type CacheStatus string
+
+const (
+	CacheHit  CacheStatus = "HIT"
+	CacheMiss CacheStatus = "MISS"
+)
+
+// Metadata carries details about how a suggestions response was produced.
+// UmbrellaAPI decorators record them so that the request handler can expose them as response headers.
+type Metadata struct {
+	mu              sync.Mutex
+	cacheStatus     CacheStatus
+	failedProviders []string
+	variants        map[string]string
+}
+
+// ContextWithMetadata returns a context carrying an empty Metadata, together with the Metadata itself.
+func ContextWithMetadata(ctx context.Context) (context.Context, *Metadata) {
+	m := &Metadata{}
+	return context.WithValue(ctx, metadataKey{}, m), m
+}
+
+// MetadataFromContext returns the Metadata attached to the context, or nil if there is none.
+// All Metadata methods are safe to call on a nil receiver.
+func MetadataFromContext(ctx context.Context) *Metadata {
+	m, _ := ctx.Value(metadataKey{}).(*Metadata)
+	return m
+}
+
+func (m *Metadata) SetCacheStatus(status CacheStatus) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheStatus = status
+}
+
+func (m *Metadata) CacheStatus() CacheStatus {
+	if m == nil {
+		return ""
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cacheStatus
+}
+
+// AddFailedProvider records a suggestion provider which failed while the others answered.
+func (m *Metadata) AddFailedProvider(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failedProviders = append(m.failedProviders, name)
+}
+
+// FailedProviders returns the suggestion providers missing from a response, making it partial.
+func (m *Metadata) FailedProviders() []string {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.failedProviders...)
+}
+
+// SetVariant records the variant a suggestion provider routed the call to.
+func (m *Metadata) SetVariant(provider string, variant string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.variants == nil {
+		m.variants = map[string]string{}
+	}
+	m.variants[provider] = variant
+}
+
+// Variants returns the routed variants as provider=variant pairs, sorted by provider.
+func (m *Metadata) Variants() []string {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	variants := make([]string, 0, len(m.variants))
+	for provider, variant := range m.variants {
+		variants = append(variants, provider+"="+variant)
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// variantsByProvider returns a copy of the routed variants, keyed by provider.
+func (m *Metadata) variantsByProvider() map[string]string {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.variants) == 0 {
+		return nil
+	}
+	variants := make(map[string]string, len(m.variants))
+	for provider, variant := range m.variants {
+		variants[provider] = variant
+	}
+	return variants
+}
@@ -10,9 +10,9 @@ type MockSuggestionsUmbrellaAPI struct {
 	mock.Mock
 }
 
-func (_mu *MockSuggestionsUmbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (suggestion []byte, err error) {
+func (_mu *MockSuggestionsUmbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (suggestion *SuggestionsResponse, err error) {
 	ret := _mu.Called(ctx, content)
-	r1 := ret.Get(0).([]byte)
+	r1 := ret.Get(0).(*SuggestionsResponse)
 	rErr := ret.Error(1)
 	return r1, rErr
 }
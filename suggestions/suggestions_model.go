@@ -0,0 +1,280 @@
+package suggestions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSuggestions is returned when the umbrella response does not conform to the suggestions schema.
+var ErrInvalidSuggestions = errors.New("suggestions response does not match the expected schema")
+
+// Suggestion is a single concept suggested for a piece of content, as described in _ft/api.yml
+type Suggestion struct {
+	ID         string `json:"id"`
+	Predicate  string `json:"predicate"`
+	Type       string `json:"type,omitempty"`
+	APIURL     string `json:"apiUrl,omitempty"`
+	PrefLabel  string `json:"prefLabel,omitempty"`
+	IsFTAuthor *bool  `json:"isFTAuthor,omitempty"`
+	// Providers are the names of the suggestion providers which proposed the concept.
+	Providers []string `json:"providers,omitempty"`
+	// Extra holds the properties the umbrella returned which are not modelled above, served back as they were.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// order lists the properties in the order they were received, so that they are served back in the same order
+	order []string
+}
+
+// SuggestionsResponse is the payload returned by the Suggestions Umbrella and served back to our clients.
+type SuggestionsResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+	// Extra holds the properties the umbrella returned which are not modelled above, served back as they were.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	order []string
+}
+
+// DecodeSuggestionsResponse reads a suggestions payload and checks it against the suggestions schema.
+// The properties which are not modelled are kept, along with the order of all of them, so that encoding the
+// response again does not change its wire format.
+func DecodeSuggestionsResponse(r io.Reader) (*SuggestionsResponse, error) {
+	resp := &SuggestionsResponse{}
+	if err := json.NewDecoder(r).Decode(resp); err != nil {
+		return nil, fmt.Errorf("failed decoding suggestions response: %w", err)
+	}
+	// a missing and a null suggestions property are both left nil, while an empty one is not
+	if resp.Suggestions == nil {
+		return nil, fmt.Errorf("%w: missing required property suggestions", ErrInvalidSuggestions)
+	}
+
+	if err := resp.Validate(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *Suggestion) UnmarshalJSON(data []byte) error {
+	type plain Suggestion
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	extra, order, err := unmodelledMembers(data, suggestionProperties)
+	if err != nil {
+		return err
+	}
+
+	*s = Suggestion(p)
+	s.Extra, s.order = extra, order
+	return nil
+}
+
+func (s Suggestion) MarshalJSON() ([]byte, error) {
+	type plain Suggestion
+	return marshalMembers(plain(s), s.Extra, s.order)
+}
+
+func (r *SuggestionsResponse) UnmarshalJSON(data []byte) error {
+	type plain SuggestionsResponse
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	extra, order, err := unmodelledMembers(data, suggestionsResponseProperties)
+	if err != nil {
+		return err
+	}
+
+	*r = SuggestionsResponse(p)
+	r.Extra, r.order = extra, order
+	return nil
+}
+
+func (r SuggestionsResponse) MarshalJSON() ([]byte, error) {
+	type plain SuggestionsResponse
+	return marshalMembers(plain(r), r.Extra, r.order)
+}
+
+// Validate checks that every suggestion carries the properties required by the suggestions schema.
+func (r *SuggestionsResponse) Validate() error {
+	var problems []string
+	for i, s := range r.Suggestions {
+		if s.ID == "" {
+			problems = append(problems, fmt.Sprintf("suggestions[%d] is missing required property id", i))
+		}
+		if s.Predicate == "" {
+			problems = append(problems, fmt.Sprintf("suggestions[%d] is missing required property predicate", i))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidSuggestions, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Encode writes the suggestions response in its wire format.
+func (r *SuggestionsResponse) Encode(w io.Writer) error {
+	resp := *r
+	if resp.Suggestions == nil {
+		resp.Suggestions = []Suggestion{}
+	}
+
+	// concept labels and urls are served as the umbrella returned them, without escaping their HTML characters
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(&resp)
+}
+
+// suggestionProperties and suggestionsResponseProperties are the modelled properties, lower-cased as they are
+// matched regardless of their case when decoding.
+var (
+	suggestionProperties          = jsonProperties(reflect.TypeOf(Suggestion{}))
+	suggestionsResponseProperties = jsonProperties(reflect.TypeOf(SuggestionsResponse{}))
+)
+
+func jsonProperties(t reflect.Type) map[string]bool {
+	properties := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			properties[strings.ToLower(name)] = true
+		}
+	}
+	return properties
+}
+
+// member is a property of a JSON object.
+type member struct {
+	key   string
+	value json.RawMessage
+}
+
+// objectMembers returns the properties of the JSON object, in their order.
+func objectMembers(data []byte) ([]member, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object: %w", err)
+	}
+
+	var members []member
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, member{key: tok.(string), value: value})
+	}
+	return members, nil
+}
+
+// unmodelledMembers returns the properties of the JSON object which are not modelled, and the order of all of them.
+func unmodelledMembers(data []byte, modelled map[string]bool) (map[string]json.RawMessage, []string, error) {
+	if string(data) == "null" {
+		return nil, nil, nil
+	}
+	members, err := objectMembers(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var extra map[string]json.RawMessage
+	order := make([]string, 0, len(members))
+	for _, m := range members {
+		order = append(order, m.key)
+		if modelled[strings.ToLower(m.key)] {
+			continue
+		}
+		if extra == nil {
+			extra = map[string]json.RawMessage{}
+		}
+		extra[m.key] = m.value
+	}
+	return extra, order, nil
+}
+
+// marshalMembers encodes the modelled properties of v along with the extra ones, in the given order. The properties
+// missing from it, such as the ones added since v was decoded, follow in the order of the model, then of their names.
+func marshalMembers(v interface{}, extra map[string]json.RawMessage, order []string) ([]byte, error) {
+	data, err := marshalUnescaped(v)
+	if err != nil || (len(extra) == 0 && len(order) == 0) {
+		return data, err
+	}
+	modelled, err := objectMembers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]json.RawMessage, len(modelled)+len(extra))
+	keys := make([]string, 0, len(modelled)+len(extra))
+	for _, m := range modelled {
+		values[m.key] = m.value
+		keys = append(keys, m.key)
+	}
+	extraKeys := make([]string, 0, len(extra))
+	for key, value := range extra {
+		if _, found := values[key]; !found {
+			values[key] = value
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	sort.Strings(extraKeys)
+	keys = append(keys, extraKeys...)
+
+	var buf bytes.Buffer
+	written := make(map[string]bool, len(values))
+	write := func(key string) error {
+		value, found := values[key]
+		if !found || written[key] {
+			return nil
+		}
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+		written[key] = true
+		encodedKey, err := marshalUnescaped(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(value)
+		return nil
+	}
+
+	buf.WriteByte('{')
+	for _, key := range order {
+		if err := write(key); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range keys {
+		if err := write(key); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func marshalUnescaped(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,116 @@
+package suggestions
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/draft-content-suggestions/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSuggestionsResponse(t *testing.T) {
+	resp, err := DecodeSuggestionsResponse(strings.NewReader(mocks.MockSuggestions))
+	assert.NoError(t, err)
+
+	if assert.Len(t, resp.Suggestions, 2) {
+		s := resp.Suggestions[1]
+		assert.Equal(t, "http://www.ft.com/thing/9a5e3b4a-55da-498c-816f-9c534e1392bd", s.ID)
+		assert.Equal(t, "http://www.ft.com/ontology/annotation/mentions", s.Predicate)
+		assert.Equal(t, "http://www.ft.com/ontology/person/Person", s.Type)
+		assert.Equal(t, "http://api.ft.com/people/9a5e3b4a-55da-498c-816f-9c534e1392bd", s.APIURL)
+		assert.Equal(t, "Lawrence Summers", s.PrefLabel)
+		if assert.NotNil(t, s.IsFTAuthor) {
+			assert.True(t, *s.IsFTAuthor)
+		}
+	}
+}
+
+func TestDecodeSuggestionsResponseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{
+			name:    "Missing suggestions",
+			payload: `{}`,
+		},
+		{
+			name:    "Null suggestions",
+			payload: `{"suggestions": null}`,
+		},
+		{
+			name:    "Missing id",
+			payload: `{"suggestions": [{"predicate": "http://www.ft.com/ontology/annotation/about"}]}`,
+		},
+		{
+			name:    "Missing predicate",
+			payload: `{"suggestions": [{"id": "http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := DecodeSuggestionsResponse(strings.NewReader(test.payload))
+
+			assert.True(t, errors.Is(err, ErrInvalidSuggestions))
+			assert.Nil(t, resp)
+		})
+	}
+}
+
+func TestDecodeSuggestionsResponseMalformed(t *testing.T) {
+	resp, err := DecodeSuggestionsResponse(strings.NewReader(`{"suggestions": [`))
+
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidSuggestions))
+	assert.Nil(t, resp)
+}
+
+func TestSuggestionsResponseEncodeRoundTrip(t *testing.T) {
+	payload := `{"suggestions":[{"id":"http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a","predicate":"http://www.ft.com/ontology/annotation/mentions","type":"http://www.ft.com/ontology/person/Person","apiUrl":"http://api.ft.com/people/6f14ea94-690f-3ed4-98c7-b926683c735a","prefLabel":"Donald Kaberuka","isFTAuthor":false},{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"http://www.ft.com/ontology/annotation/about","type":"http://www.ft.com/ontology/Topic","prefLabel":"US stocks"}]}
+`
+	resp, err := DecodeSuggestionsResponse(strings.NewReader(payload))
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, resp.Encode(buf))
+	assert.Equal(t, payload, buf.String())
+}
+
+func TestSuggestionsResponseEncodeEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, (&SuggestionsResponse{}).Encode(buf))
+	assert.Equal(t, "{\"suggestions\":[]}\n", buf.String())
+}
+
+func TestSuggestionsResponseEncodeKeepsTheUmbrellaWireFormat(t *testing.T) {
+	// as returned by the umbrella: its own property order, properties the model does not know, HTML characters and
+	// non-ASCII labels
+	payload := `{"suggestions":[` +
+		`{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/6f14ea94-690f-3ed4-98c7-b926683c735a","apiUrl":"http://api.ft.com/people/6f14ea94-690f-3ed4-98c7-b926683c735a","prefLabel":"Donald Kaberuka","type":"http://www.ft.com/ontology/person/Person","isFTAuthor":false,"score":0.87,"extra":{"source":"tme","aliases":["D. Kaberuka"]}},` +
+		`{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","apiUrl":"http://api.ft.com/things/d7113d1d-ed66-3adf-9910-1f62b2c40e6a?type=topic&lang=en","prefLabel":"M&A <Deals> – Société Générale","type":"http://www.ft.com/ontology/Topic"}` +
+		`],"requestId":"tid_6f14ea94","model":{"version":"2.4.1"}}` + "\n"
+
+	resp, err := DecodeSuggestionsResponse(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `0.87`, string(resp.Suggestions[0].Extra["score"]))
+	assert.JSONEq(t, `"tid_6f14ea94"`, string(resp.Extra["requestId"]))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, resp.Encode(buf))
+	assert.Equal(t, payload, buf.String())
+}
+
+func TestSuggestionsResponseEncodeAddsNewPropertiesLast(t *testing.T) {
+	payload := `{"suggestions":[{"predicate":"about","id":"id","score":1}]}`
+	resp, err := DecodeSuggestionsResponse(strings.NewReader(payload))
+	assert.NoError(t, err)
+
+	resp.Suggestions[0].Providers = []string{"umbrella"}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, resp.Encode(buf))
+	assert.Equal(t, `{"suggestions":[{"predicate":"about","id":"id","score":1,"providers":["umbrella"]}]}`+"\n", buf.String())
+}
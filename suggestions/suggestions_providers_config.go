@@ -0,0 +1,146 @@
+package suggestions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Financial-Times/draft-content-suggestions/endpointessentials"
+	"gopkg.in/yaml.v3"
+)
+
+// ProvidersConfig lists the suggestion providers, in the order their suggestions are merged.
+type ProvidersConfig struct {
+	Providers []ProviderConfig `yaml:"providers"`
+}
+
+type ProviderConfig struct {
+	Name        string `yaml:"name"`
+	Endpoint    string `yaml:"end-point,omitempty"`
+	GTGEndpoint string `yaml:"gtg-end-point,omitempty"`
+	// Variants are the endpoints the calls are routed to by weight, in place of the end-point of the provider.
+	Variants []VariantConfig `yaml:"variants,omitempty"`
+	// Timeout bounds the calls to the provider, e.g. 2s. It is unbounded when left empty.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// DeliveryBasicAuth sends the basic auth of the delivery UPP clusters to the provider.
+	DeliveryBasicAuth bool `yaml:"delivery-basic-auth,omitempty"`
+}
+
+type VariantConfig struct {
+	Name        string `yaml:"name"`
+	Endpoint    string `yaml:"end-point"`
+	GTGEndpoint string `yaml:"gtg-end-point"`
+	// Weight is the share of the drafts routed to the variant, relative to the weights of the other variants.
+	Weight int `yaml:"weight"`
+}
+
+// Dependency is the name of the downstream dependency of the variant, which its client and metrics are named after.
+func (p ProviderConfig) Dependency(variant string) string {
+	if variant == "" {
+		return p.Name
+	}
+	return p.Name + "/" + variant
+}
+
+// Dependencies returns the endpoints of the downstream dependencies of all the providers and their variants,
+// by name.
+func (c *ProvidersConfig) Dependencies() map[string]string {
+	dependencies := map[string]string{}
+	for _, p := range c.Providers {
+		if len(p.Variants) == 0 {
+			dependencies[p.Dependency("")] = p.Endpoint
+		}
+		for _, v := range p.Variants {
+			dependencies[p.Dependency(v.Name)] = v.Endpoint
+		}
+	}
+	return dependencies
+}
+
+// ReadProvidersConfig reads and validates a suggestion providers YAML file.
+func ReadProvidersConfig(yml string) (*ProvidersConfig, error) {
+	by, err := os.ReadFile(yml)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg ProvidersConfig
+	if err := yaml.Unmarshal(by, &cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks that every provider has a unique name, well-formed endpoints and no negative timeout.
+func (c *ProvidersConfig) Validate() error {
+	if len(c.Providers) == 0 {
+		return errors.New("no suggestion provider configured")
+	}
+
+	var errs []error
+	names := make(map[string]bool, len(c.Providers))
+	for i, p := range c.Providers {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("providers[%d] has no name", i))
+		case names[p.Name]:
+			errs = append(errs, fmt.Errorf("providers[%d] repeats the name %s", i, p.Name))
+		}
+		names[p.Name] = true
+
+		if len(p.Variants) > 0 {
+			errs = append(errs, validateVariants(fmt.Sprintf("providers[%d]", i), p)...)
+		} else {
+			errs = append(errs, validateEndpoints(fmt.Sprintf("providers[%d]", i), p.Endpoint, p.GTGEndpoint)...)
+		}
+		if p.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("providers[%d] has a negative timeout", i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateVariants(prefix string, p ProviderConfig) []error {
+	var errs []error
+	if p.Endpoint != "" || p.GTGEndpoint != "" {
+		errs = append(errs, fmt.Errorf("%s has both end-points and variants", prefix))
+	}
+	if len(p.Variants) < 2 {
+		errs = append(errs, fmt.Errorf("%s needs at least two variants to route to", prefix))
+	}
+
+	names := make(map[string]bool, len(p.Variants))
+	for j, v := range p.Variants {
+		variantPrefix := fmt.Sprintf("%s.variants[%d]", prefix, j)
+		switch {
+		case v.Name == "":
+			errs = append(errs, fmt.Errorf("%s has no name", variantPrefix))
+		case names[v.Name]:
+			errs = append(errs, fmt.Errorf("%s repeats the name %s", variantPrefix, v.Name))
+		}
+		names[v.Name] = true
+
+		errs = append(errs, validateEndpoints(variantPrefix, v.Endpoint, v.GTGEndpoint)...)
+		if v.Weight <= 0 {
+			errs = append(errs, fmt.Errorf("%s has no positive weight", variantPrefix))
+		}
+	}
+	return errs
+}
+
+func validateEndpoints(prefix string, endpoint string, gtgEndpoint string) []error {
+	var errs []error
+	if err := endpointessentials.ValidateEndpoint(endpoint); err != nil {
+		errs = append(errs, fmt.Errorf("%s end-point: %w", prefix, err))
+	}
+	if err := endpointessentials.ValidateEndpoint(gtgEndpoint); err != nil {
+		errs = append(errs, fmt.Errorf("%s gtg-end-point: %w", prefix, err))
+	}
+	return errs
+}
@@ -0,0 +1,118 @@
+package suggestions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProvidersConfig(t *testing.T) {
+	yml := writeProvidersConfig(t, `
+providers:
+  - name: umbrella
+    end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest
+    gtg-end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest/__gtg
+    delivery-basic-auth: true
+  - name: entity-extractor
+    end-point: http://entity-extractor:8080/suggest
+    gtg-end-point: http://entity-extractor:8080/__gtg
+    timeout: 2s
+`)
+
+	cfg, err := ReadProvidersConfig(yml)
+	assert.NoError(t, err)
+	assert.Equal(t, []ProviderConfig{
+		{
+			Name:              "umbrella",
+			Endpoint:          "https://upp-staging-delivery-glb.upp.ft.com/content/suggest",
+			GTGEndpoint:       "https://upp-staging-delivery-glb.upp.ft.com/content/suggest/__gtg",
+			DeliveryBasicAuth: true,
+		},
+		{
+			Name:        "entity-extractor",
+			Endpoint:    "http://entity-extractor:8080/suggest",
+			GTGEndpoint: "http://entity-extractor:8080/__gtg",
+			Timeout:     2 * time.Second,
+		},
+	}, cfg.Providers)
+}
+
+func TestReadProvidersConfigReportsAllProblems(t *testing.T) {
+	yml := writeProvidersConfig(t, `
+providers:
+  - name: umbrella
+    end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest
+    gtg-end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest/__gtg
+  - name: umbrella
+    end-point: entity-extractor:8080/suggest
+    gtg-end-point: http://entity-extractor:8080/__gtg
+    timeout: -1s
+`)
+
+	_, err := ReadProvidersConfig(yml)
+	assert.EqualError(t, err, "providers[1] repeats the name umbrella\n"+
+		"providers[1] end-point: missing scheme in endpoint: entity-extractor:8080/suggest\n"+
+		"providers[1] has a negative timeout")
+}
+
+func TestReadProvidersConfigWithoutProviders(t *testing.T) {
+	_, err := ReadProvidersConfig(writeProvidersConfig(t, "providers: []\n"))
+	assert.EqualError(t, err, "no suggestion provider configured")
+}
+
+func writeProvidersConfig(t *testing.T, content string) string {
+	yml := filepath.Join(t.TempDir(), "providers.yml")
+	if err := os.WriteFile(yml, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return yml
+}
+
+func TestReadProvidersConfigWithVariants(t *testing.T) {
+	yml := writeProvidersConfig(t, `
+providers:
+  - name: umbrella
+    delivery-basic-auth: true
+    variants:
+      - name: current
+        end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest
+        gtg-end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest/__gtg
+        weight: 90
+      - name: candidate
+        end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest-v2
+        gtg-end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest-v2/__gtg
+        weight: 10
+  - name: entity-extractor
+    end-point: http://entity-extractor:8080/suggest
+    gtg-end-point: http://entity-extractor:8080/__gtg
+`)
+
+	cfg, err := ReadProvidersConfig(yml)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Providers[0].Variants, 2)
+	assert.Equal(t, map[string]string{
+		"umbrella/current":   "https://upp-staging-delivery-glb.upp.ft.com/content/suggest",
+		"umbrella/candidate": "https://upp-staging-delivery-glb.upp.ft.com/content/suggest-v2",
+		"entity-extractor":   "http://entity-extractor:8080/suggest",
+	}, cfg.Dependencies())
+}
+
+func TestReadProvidersConfigReportsVariantProblems(t *testing.T) {
+	yml := writeProvidersConfig(t, `
+providers:
+  - name: umbrella
+    end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest
+    variants:
+      - name: current
+        end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest
+        gtg-end-point: https://upp-staging-delivery-glb.upp.ft.com/content/suggest/__gtg
+`)
+
+	_, err := ReadProvidersConfig(yml)
+	assert.EqualError(t, err, "providers[0] has both end-points and variants\n"+
+		"providers[0] needs at least two variants to route to\n"+
+		"providers[0].variants[0] has no positive weight")
+}
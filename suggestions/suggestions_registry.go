@@ -0,0 +1,192 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/draft-content-suggestions/tracing"
+)
+
+// Provider is a named source of suggestions, such as the Suggestions Umbrella or an internal entity extractor.
+type Provider struct {
+	Name string
+	API  UmbrellaAPI
+	// Timeout bounds the calls to the provider, so that a slow one does not hold the response back. 0 means no bound.
+	Timeout time.Duration
+}
+
+// NewProviderRegistry returns an UmbrellaAPI calling all the providers in parallel and merging their suggestions.
+// Suggestions of the same concept are deduplicated by id, keeping the first one in the order of the providers,
+// and list every provider which proposed them. The call only fails when all the providers fail; the ones failing
+// alongside successful ones are recorded in the Metadata of the context.
+// A single provider has nothing to be merged with, so its API is returned as it is, only bounded by its timeout,
+// and its suggestions are served unchanged.
+func NewProviderRegistry(providers []Provider) (UmbrellaAPI, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no suggestion provider configured")
+	}
+
+	names := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		if p.Name == "" {
+			return nil, errors.New("suggestion provider without a name")
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("duplicate suggestion provider %s", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	if len(providers) == 1 {
+		if providers[0].Timeout <= 0 {
+			return providers[0].API, nil
+		}
+		return &boundedProvider{UmbrellaAPI: providers[0].API, timeout: providers[0].Timeout}, nil
+	}
+
+	return &providerRegistry{providers: providers}, nil
+}
+
+// boundedProvider bounds the calls to a provider served on its own to its timeout.
+type boundedProvider struct {
+	UmbrellaAPI
+	timeout time.Duration
+}
+
+func (b *boundedProvider) FetchSuggestions(ctx context.Context, content []byte) (*SuggestionsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.UmbrellaAPI.FetchSuggestions(ctx, content)
+}
+
+type providerRegistry struct {
+	providers []Provider
+}
+
+type providerResult struct {
+	resp *SuggestionsResponse
+	err  error
+}
+
+func (r *providerRegistry) FetchSuggestions(ctx context.Context, content []byte) (suggestion *SuggestionsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "suggestions.FetchFromProviders")
+	defer func() { tracing.End(span, err) }()
+
+	results := make([]providerResult, len(r.providers))
+	var wg sync.WaitGroup
+	for i, p := range r.providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			results[i].resp, results[i].err = p.fetch(ctx, content)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var errs []error
+	var failed []string
+	merged := &SuggestionsResponse{Suggestions: []Suggestion{}}
+	index := map[string]int{}
+	for i, res := range results {
+		name := r.providers[i].Name
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("suggestion provider %s: %w", name, res.err))
+			failed = append(failed, name)
+			continue
+		}
+
+		for _, s := range res.resp.Suggestions {
+			if at, found := index[s.ID]; found {
+				merged.Suggestions[at].addProvider(name)
+				continue
+			}
+			s.Providers = nil
+			s.addProvider(name)
+			index[s.ID] = len(merged.Suggestions)
+			merged.Suggestions = append(merged.Suggestions, s)
+		}
+	}
+
+	if len(failed) == len(r.providers) {
+		return nil, errors.Join(errs...)
+	}
+	meta := MetadataFromContext(ctx)
+	for _, name := range failed {
+		meta.AddFailedProvider(name)
+	}
+
+	return merged, nil
+}
+
+func (p Provider) fetch(ctx context.Context, content []byte) (*SuggestionsResponse, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	return p.API.FetchSuggestions(ctx, content)
+}
+
+func (s *Suggestion) addProvider(name string) {
+	for _, p := range s.Providers {
+		if p == name {
+			return
+		}
+	}
+	s.Providers = append(s.Providers, name)
+}
+
+// Endpoint lists the endpoints of all the providers.
+func (r *providerRegistry) Endpoint() string {
+	endpoints := make([]string, 0, len(r.providers))
+	for _, p := range r.providers {
+		endpoints = append(endpoints, p.API.Endpoint())
+	}
+	return strings.Join(endpoints, ", ")
+}
+
+// IsGTG is good-to-go as long as one provider is, as suggestions can still be served from it.
+func (r *providerRegistry) IsGTG(ctx context.Context) (string, error) {
+	errs := make([]error, len(r.providers))
+	var wg sync.WaitGroup
+	for i, p := range r.providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			if _, err := p.API.IsGTG(ctx); err != nil {
+				errs[i] = fmt.Errorf("suggestion provider %s: %w", p.Name, err)
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, r.providers[i].Name)
+		}
+	}
+
+	switch {
+	case len(failed) == len(r.providers):
+		return "", errors.Join(errs...)
+	case len(failed) > 0:
+		return fmt.Sprintf("%d of %d suggestion providers are healthy, not good-to-go: %s",
+			len(r.providers)-len(failed), len(r.providers), strings.Join(failed, ", ")), nil
+	default:
+		return "All suggestion providers are healthy", nil
+	}
+}
+
+func (r *providerRegistry) IsValid() error {
+	for _, p := range r.providers {
+		if err := p.API.IsValid(); err != nil {
+			return fmt.Errorf("suggestion provider %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
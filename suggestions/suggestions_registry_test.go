@@ -0,0 +1,142 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProviderRegistryMergesAndDedupesSuggestions(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+
+	umbrella := &MockSuggestionsUmbrellaAPI{}
+	umbrella.On("FetchSuggestions", mock.Anything, content).Return(&SuggestionsResponse{Suggestions: []Suggestion{
+		{ID: "person", Predicate: "about", PrefLabel: "From the umbrella"},
+		{ID: "organisation", Predicate: "mentions"},
+	}}, nil)
+	extractor := &MockSuggestionsUmbrellaAPI{}
+	extractor.On("FetchSuggestions", mock.Anything, content).Return(&SuggestionsResponse{Suggestions: []Suggestion{
+		{ID: "topic", Predicate: "about"},
+		{ID: "person", Predicate: "mentions", PrefLabel: "From the extractor"},
+	}}, nil)
+
+	registry, err := NewProviderRegistry([]Provider{{Name: "umbrella", API: umbrella}, {Name: "extractor", API: extractor}})
+	assert.NoError(t, err)
+
+	ctx, meta := ContextWithMetadata(context.Background())
+	resp, err := registry.FetchSuggestions(ctx, content)
+	assert.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{ID: "person", Predicate: "about", PrefLabel: "From the umbrella", Providers: []string{"umbrella", "extractor"}},
+		{ID: "organisation", Predicate: "mentions", Providers: []string{"umbrella"}},
+		{ID: "topic", Predicate: "about", Providers: []string{"extractor"}},
+	}, resp.Suggestions)
+	assert.Empty(t, meta.FailedProviders())
+}
+
+func TestProviderRegistryRecordsFailedProviders(t *testing.T) {
+	content := []byte(`{}`)
+
+	umbrella := &MockSuggestionsUmbrellaAPI{}
+	umbrella.On("FetchSuggestions", mock.Anything, content).Return(&SuggestionsResponse{Suggestions: []Suggestion{{ID: "person", Predicate: "about"}}}, nil)
+	extractor := &MockSuggestionsUmbrellaAPI{}
+	extractor.On("FetchSuggestions", mock.Anything, content).Return((*SuggestionsResponse)(nil), errors.New("boom"))
+
+	registry, err := NewProviderRegistry([]Provider{{Name: "umbrella", API: umbrella}, {Name: "extractor", API: extractor}})
+	assert.NoError(t, err)
+
+	ctx, meta := ContextWithMetadata(context.Background())
+	resp, err := registry.FetchSuggestions(ctx, content)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Suggestions, 1)
+	assert.Equal(t, []string{"extractor"}, meta.FailedProviders())
+}
+
+func TestProviderRegistryFailsWhenAllProvidersFail(t *testing.T) {
+	content := []byte(`{}`)
+
+	umbrella := &MockSuggestionsUmbrellaAPI{}
+	umbrella.On("FetchSuggestions", mock.Anything, content).Return((*SuggestionsResponse)(nil), context.DeadlineExceeded)
+	extractor := &MockSuggestionsUmbrellaAPI{}
+	extractor.On("FetchSuggestions", mock.Anything, content).Return((*SuggestionsResponse)(nil), errors.New("boom"))
+
+	registry, err := NewProviderRegistry([]Provider{{Name: "umbrella", API: umbrella}, {Name: "extractor", API: extractor}})
+	assert.NoError(t, err)
+
+	_, err = registry.FetchSuggestions(context.Background(), content)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "suggestion provider extractor: boom")
+}
+
+func TestProviderRegistryAppliesProviderTimeouts(t *testing.T) {
+	content := []byte(`{}`)
+
+	umbrella := &MockSuggestionsUmbrellaAPI{}
+	umbrella.On("FetchSuggestions", mock.Anything, content).Return(&SuggestionsResponse{Suggestions: []Suggestion{{ID: "person", Predicate: "about"}}}, nil)
+	slow := &MockSuggestionsUmbrellaAPI{}
+	slow.On("FetchSuggestions", mock.Anything, content).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return((*SuggestionsResponse)(nil), context.DeadlineExceeded)
+
+	registry, err := NewProviderRegistry([]Provider{
+		{Name: "umbrella", API: umbrella},
+		{Name: "slow", API: slow, Timeout: 10 * time.Millisecond},
+	})
+	assert.NoError(t, err)
+
+	ctx, meta := ContextWithMetadata(context.Background())
+	resp, err := registry.FetchSuggestions(ctx, content)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Suggestions, 1)
+	assert.Equal(t, []string{"slow"}, meta.FailedProviders())
+}
+
+func TestProviderRegistryIsGTG(t *testing.T) {
+	healthy := &MockSuggestionsUmbrellaAPI{}
+	healthy.On("IsGTG", mock.Anything).Return("healthy", nil)
+	unhealthy := &MockSuggestionsUmbrellaAPI{}
+	unhealthy.On("IsGTG", mock.Anything).Return("", errors.New("boom"))
+
+	registry, err := NewProviderRegistry([]Provider{{Name: "umbrella", API: healthy}, {Name: "extractor", API: unhealthy}})
+	assert.NoError(t, err)
+	msg, err := registry.IsGTG(context.Background())
+	assert.NoError(t, err, "suggestions can still be served by the umbrella")
+	assert.Equal(t, "1 of 2 suggestion providers are healthy, not good-to-go: extractor", msg)
+
+	registry, err = NewProviderRegistry([]Provider{{Name: "extractor", API: unhealthy}, {Name: "umbrella", API: unhealthy}})
+	assert.NoError(t, err)
+	_, err = registry.IsGTG(context.Background())
+	assert.EqualError(t, err, "suggestion provider extractor: boom\nsuggestion provider umbrella: boom")
+}
+
+func TestProviderRegistryServesASingleProviderUnchanged(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	expected := &SuggestionsResponse{Suggestions: []Suggestion{{ID: "id", Predicate: "about"}}}
+	umbrella := &MockSuggestionsUmbrellaAPI{}
+	umbrella.On("FetchSuggestions", mock.Anything, content).Return(expected, nil)
+
+	registry, err := NewProviderRegistry([]Provider{{Name: "umbrella", API: umbrella}})
+	assert.NoError(t, err)
+	assert.Same(t, umbrella, registry)
+
+	registry, err = NewProviderRegistry([]Provider{{Name: "umbrella", API: umbrella, Timeout: time.Second}})
+	assert.NoError(t, err)
+	resp, err := registry.FetchSuggestions(context.Background(), content)
+	assert.NoError(t, err)
+	assert.Same(t, expected, resp, "the suggestions are not attributed to the only provider")
+	assert.Nil(t, resp.Suggestions[0].Providers)
+}
+
+func TestNewProviderRegistryRejectsDuplicateNames(t *testing.T) {
+	api := &MockSuggestionsUmbrellaAPI{}
+
+	_, err := NewProviderRegistry([]Provider{{Name: "umbrella", API: api}, {Name: "umbrella", API: api}})
+	assert.EqualError(t, err, "duplicate suggestion provider umbrella")
+
+	_, err = NewProviderRegistry(nil)
+	assert.EqualError(t, err, "no suggestion provider configured")
+}
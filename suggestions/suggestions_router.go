@@ -0,0 +1,125 @@
+package suggestions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// Variant is one of the endpoints a provider routes its calls to, with its share of the drafts.
+type Variant struct {
+	Name   string
+	API    UmbrellaAPI
+	Weight int
+}
+
+// NewWeightedRouter returns an UmbrellaAPI routing every call of the provider to one of its variants, in
+// proportion to their weights. Routing is sticky: a draft always goes to the same variant, picked from a hash of
+// its uuid, as long as the weights do not change. The chosen variant is recorded in the Metadata of the context
+// and counted in the given metrics registry.
+func NewWeightedRouter(provider string, variants []Variant, registry metrics.Registry) (UmbrellaAPI, error) {
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("suggestion provider %s needs at least two variants to route to", provider)
+	}
+
+	r := &weightedRouter{provider: provider, variants: variants}
+	names := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		if names[v.Name] {
+			return nil, fmt.Errorf("duplicate variant %s of suggestion provider %s", v.Name, provider)
+		}
+		names[v.Name] = true
+		if v.Weight <= 0 {
+			return nil, fmt.Errorf("variant %s of suggestion provider %s has no positive weight", v.Name, provider)
+		}
+		r.totalWeight += v.Weight
+		r.calls = append(r.calls, variantCounter(provider, v.Name, registry))
+	}
+
+	return r, nil
+}
+
+// variantCounter counts the drafts routed to the variant of the provider, including the ones served from the cache.
+func variantCounter(provider string, variant string, registry metrics.Registry) metrics.Counter {
+	return metrics.GetOrRegisterCounter(fmt.Sprintf("suggestions.variants.%s.%s.calls", provider, variant), registry)
+}
+
+type weightedRouter struct {
+	provider    string
+	variants    []Variant
+	totalWeight int
+	calls       []metrics.Counter
+}
+
+func (r *weightedRouter) FetchSuggestions(ctx context.Context, content []byte) (*SuggestionsResponse, error) {
+	i := r.route(content)
+	r.calls[i].Inc(1)
+	MetadataFromContext(ctx).SetVariant(r.provider, r.variants[i].Name)
+
+	return r.variants[i].API.FetchSuggestions(ctx, content)
+}
+
+// route returns the index of the variant of the draft. Each variant owns a range of buckets, following the order
+// of the variants, so raising the weight of the last one only moves drafts to it.
+func (r *weightedRouter) route(content []byte) int {
+	h := fnv.New32a()
+	h.Write([]byte(routingKey(content)))
+	bucket := int(h.Sum32() % uint32(r.totalWeight))
+
+	for i, v := range r.variants {
+		if bucket < v.Weight {
+			return i
+		}
+		bucket -= v.Weight
+	}
+	return len(r.variants) - 1
+}
+
+// routingKey is the uuid of the draft, or the hash of the whole content when it has none.
+func routingKey(content []byte) string {
+	var draft struct {
+		UUID string `json:"uuid"`
+	}
+	if err := json.Unmarshal(content, &draft); err != nil || draft.UUID == "" {
+		return contentHash(content)
+	}
+	return draft.UUID
+}
+
+// Endpoint lists the endpoints of all the variants.
+func (r *weightedRouter) Endpoint() string {
+	endpoints := make([]string, 0, len(r.variants))
+	for _, v := range r.variants {
+		endpoints = append(endpoints, v.API.Endpoint())
+	}
+	return strings.Join(endpoints, ", ")
+}
+
+// IsGTG is only good-to-go when all the variants are, as each of them serves its own share of the drafts.
+func (r *weightedRouter) IsGTG(ctx context.Context) (string, error) {
+	var errs []error
+	for _, v := range r.variants {
+		if _, err := v.API.IsGTG(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("variant %s: %w", v.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	return fmt.Sprintf("All variants of suggestion provider %s are healthy", r.provider), nil
+}
+
+func (r *weightedRouter) IsValid() error {
+	for _, v := range r.variants {
+		if err := v.API.IsValid(); err != nil {
+			return fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
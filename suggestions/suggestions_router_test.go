@@ -0,0 +1,102 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWeightedRouterIsStickyPerDraft(t *testing.T) {
+	current := &MockSuggestionsUmbrellaAPI{}
+	current.On("FetchSuggestions", mock.Anything, mock.Anything).Return(&SuggestionsResponse{}, nil)
+	candidate := &MockSuggestionsUmbrellaAPI{}
+	candidate.On("FetchSuggestions", mock.Anything, mock.Anything).Return(&SuggestionsResponse{}, nil)
+
+	registry := metrics.NewRegistry()
+	router, err := NewWeightedRouter("umbrella", []Variant{
+		{Name: "current", API: current, Weight: 90},
+		{Name: "candidate", API: candidate, Weight: 10},
+	}, registry)
+	assert.NoError(t, err)
+
+	const drafts = 1000
+	for i := 0; i < drafts; i++ {
+		content := []byte(fmt.Sprintf(`{"uuid": "36320eb6-5617-4d12-9750-%012d", "title": "first revision"}`, i))
+		revised := []byte(fmt.Sprintf(`{"uuid": "36320eb6-5617-4d12-9750-%012d", "title": "second revision"}`, i))
+
+		ctx, meta := ContextWithMetadata(context.Background())
+		_, err := router.FetchSuggestions(ctx, content)
+		assert.NoError(t, err)
+		revisedCtx, revisedMeta := ContextWithMetadata(context.Background())
+		_, err = router.FetchSuggestions(revisedCtx, revised)
+		assert.NoError(t, err)
+
+		assert.Len(t, meta.Variants(), 1)
+		assert.Equal(t, meta.Variants(), revisedMeta.Variants(), "every revision of a draft is routed to the same variant")
+	}
+
+	candidateCalls := registry.Get("suggestions.variants.umbrella.candidate.calls").(metrics.Counter).Count()
+	currentCalls := registry.Get("suggestions.variants.umbrella.current.calls").(metrics.Counter).Count()
+	assert.Equal(t, int64(2*drafts), candidateCalls+currentCalls)
+	assert.InDelta(t, 0.1, float64(candidateCalls)/float64(2*drafts), 0.03)
+}
+
+func TestWeightedRouterRampUpOnlyMovesDraftsToTheLastVariant(t *testing.T) {
+	api := &MockSuggestionsUmbrellaAPI{}
+	before, err := NewWeightedRouter("umbrella", []Variant{{Name: "current", API: api, Weight: 90}, {Name: "candidate", API: api, Weight: 10}}, metrics.NewRegistry())
+	assert.NoError(t, err)
+	after, err := NewWeightedRouter("umbrella", []Variant{{Name: "current", API: api, Weight: 50}, {Name: "candidate", API: api, Weight: 50}}, metrics.NewRegistry())
+	assert.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		content := []byte(fmt.Sprintf(`{"uuid": "36320eb6-5617-4d12-9750-%012d"}`, i))
+		if before.(*weightedRouter).route(content) == 1 {
+			assert.Equal(t, 1, after.(*weightedRouter).route(content))
+		}
+	}
+}
+
+func TestWeightedRouterRoutesDraftsWithoutUUIDByContent(t *testing.T) {
+	api := &MockSuggestionsUmbrellaAPI{}
+	router, err := NewWeightedRouter("umbrella", []Variant{{Name: "current", API: api, Weight: 1}, {Name: "candidate", API: api, Weight: 1}}, metrics.NewRegistry())
+	assert.NoError(t, err)
+
+	content := []byte(`not a draft`)
+	assert.Equal(t, router.(*weightedRouter).route(content), router.(*weightedRouter).route(content))
+}
+
+func TestWeightedRouterIsGTG(t *testing.T) {
+	healthy := &MockSuggestionsUmbrellaAPI{}
+	healthy.On("IsGTG", mock.Anything).Return("healthy", nil)
+	unhealthy := &MockSuggestionsUmbrellaAPI{}
+	unhealthy.On("IsGTG", mock.Anything).Return("", errors.New("boom"))
+
+	router, err := NewWeightedRouter("umbrella", []Variant{{Name: "current", API: healthy, Weight: 90}, {Name: "candidate", API: healthy, Weight: 10}}, metrics.NewRegistry())
+	assert.NoError(t, err)
+	msg, err := router.IsGTG(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "All variants of suggestion provider umbrella are healthy", msg)
+
+	router, err = NewWeightedRouter("umbrella", []Variant{{Name: "current", API: healthy, Weight: 90}, {Name: "candidate", API: unhealthy, Weight: 10}}, metrics.NewRegistry())
+	assert.NoError(t, err)
+	_, err = router.IsGTG(context.Background())
+	assert.EqualError(t, err, "variant candidate: boom")
+}
+
+func TestNewWeightedRouterRejectsInvalidVariants(t *testing.T) {
+	api := &MockSuggestionsUmbrellaAPI{}
+
+	_, err := NewWeightedRouter("umbrella", []Variant{{Name: "current", API: api, Weight: 1}}, metrics.NewRegistry())
+	assert.EqualError(t, err, "suggestion provider umbrella needs at least two variants to route to")
+
+	_, err = NewWeightedRouter("umbrella", []Variant{{Name: "current", API: api, Weight: 1}, {Name: "current", API: api, Weight: 1}}, metrics.NewRegistry())
+	assert.EqualError(t, err, "duplicate variant current of suggestion provider umbrella")
+
+	_, err = NewWeightedRouter("umbrella", []Variant{{Name: "current", API: api, Weight: 1}, {Name: "candidate", API: api}}, metrics.NewRegistry())
+	assert.EqualError(t, err, "variant candidate of suggestion provider umbrella has no positive weight")
+}
@@ -0,0 +1,153 @@
+package suggestions
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// ShadowSettings bound the calls made to a shadowed candidate, so that it cannot slow down the service.
+type ShadowSettings struct {
+	// Timeout bounds every call to the candidate.
+	Timeout time.Duration
+	// Concurrency is the maximum number of calls to the candidate in flight, further calls are dropped.
+	Concurrency int
+}
+
+// Diff compares the suggestions of a candidate with the ones served, by concept id.
+type Diff struct {
+	// Added are the concepts only suggested by the candidate.
+	Added []string
+	// Removed are the concepts only suggested by the primary.
+	Removed []string
+	// PredicateChanged are the concepts suggested by both with a different predicate.
+	PredicateChanged []string
+}
+
+// Empty tells whether the candidate suggested the same concepts with the same predicates.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.PredicateChanged) == 0
+}
+
+// DiffSuggestions returns the differences of the candidate suggestions from the primary ones, sorted by concept id.
+func DiffSuggestions(primary, candidate *SuggestionsResponse) Diff {
+	primaryPredicates := predicatesByID(primary)
+	candidatePredicates := predicatesByID(candidate)
+
+	var d Diff
+	for id, predicate := range candidatePredicates {
+		primaryPredicate, found := primaryPredicates[id]
+		switch {
+		case !found:
+			d.Added = append(d.Added, id)
+		case primaryPredicate != predicate:
+			d.PredicateChanged = append(d.PredicateChanged, id)
+		}
+	}
+	for id := range primaryPredicates {
+		if _, found := candidatePredicates[id]; !found {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.PredicateChanged)
+	return d
+}
+
+func predicatesByID(resp *SuggestionsResponse) map[string]string {
+	predicates := make(map[string]string, len(resp.Suggestions))
+	for _, s := range resp.Suggestions {
+		predicates[s.ID] = s.Predicate
+	}
+	return predicates
+}
+
+// NewShadowUmbrellaAPI wraps an UmbrellaAPI so that every call is also sent asynchronously to a candidate.
+// The suggestions of the candidate are never served: they are diffed with the ones of the wrapped API, and the
+// differences are logged and counted in the given metrics registry.
+func NewShadowUmbrellaAPI(api UmbrellaAPI, candidate UmbrellaAPI, settings ShadowSettings, registry metrics.Registry, log *logger.UPPLogger) UmbrellaAPI {
+	return &shadowUmbrellaAPI{
+		UmbrellaAPI: api,
+		candidate:   candidate,
+		timeout:     settings.Timeout,
+		slots:       make(chan struct{}, settings.Concurrency),
+		log:         log,
+		calls:       metrics.GetOrRegisterCounter("suggestions.shadow.calls", registry),
+		dropped:     metrics.GetOrRegisterCounter("suggestions.shadow.dropped", registry),
+		failures:    metrics.GetOrRegisterCounter("suggestions.shadow.failures", registry),
+		matches:     metrics.GetOrRegisterCounter("suggestions.shadow.matches", registry),
+		mismatches:  metrics.GetOrRegisterCounter("suggestions.shadow.mismatches", registry),
+		added:       metrics.GetOrRegisterCounter("suggestions.shadow.concepts.added", registry),
+		removed:     metrics.GetOrRegisterCounter("suggestions.shadow.concepts.removed", registry),
+		changed:     metrics.GetOrRegisterCounter("suggestions.shadow.concepts.predicate_changed", registry),
+	}
+}
+
+type shadowUmbrellaAPI struct {
+	UmbrellaAPI
+	candidate UmbrellaAPI
+	timeout   time.Duration
+	slots     chan struct{}
+	log       *logger.UPPLogger
+
+	calls, dropped, failures, matches, mismatches metrics.Counter
+	added, removed, changed                       metrics.Counter
+}
+
+func (s *shadowUmbrellaAPI) FetchSuggestions(ctx context.Context, content []byte) (*SuggestionsResponse, error) {
+	resp, err := s.UmbrellaAPI.FetchSuggestions(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		s.calls.Inc(1)
+		// the candidate is called after the response is served, so it must not be cancelled along with the request
+		go func() {
+			defer func() { <-s.slots }()
+			s.compare(context.WithoutCancel(ctx), content, resp)
+		}()
+	default:
+		s.dropped.Inc(1)
+	}
+
+	return resp, nil
+}
+
+func (s *shadowUmbrellaAPI) compare(ctx context.Context, content []byte, primary *SuggestionsResponse) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tid, _ := tidutils.GetTransactionIDFromContext(ctx)
+	log := s.log.WithTransactionID(tid).WithField("candidate", s.candidate.Endpoint())
+
+	candidate, err := s.candidate.FetchSuggestions(ctx, content)
+	if err != nil {
+		s.failures.Inc(1)
+		log.WithError(err).Warn("Shadowed suggestions candidate has failed")
+		return
+	}
+
+	d := DiffSuggestions(primary, candidate)
+	if d.Empty() {
+		s.matches.Inc(1)
+		log.Debug("Shadowed suggestions candidate matches")
+		return
+	}
+
+	s.mismatches.Inc(1)
+	s.added.Inc(int64(len(d.Added)))
+	s.removed.Inc(int64(len(d.Removed)))
+	s.changed.Inc(int64(len(d.PredicateChanged)))
+	log.WithField("added", d.Added).
+		WithField("removed", d.Removed).
+		WithField("predicateChanged", d.PredicateChanged).
+		Info("Shadowed suggestions candidate differs")
+}
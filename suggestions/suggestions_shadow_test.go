@@ -0,0 +1,114 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logger "github.com/Financial-Times/go-logger/v2"
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDiffSuggestions(t *testing.T) {
+	primary := &SuggestionsResponse{Suggestions: []Suggestion{
+		{ID: "kept", Predicate: "about"},
+		{ID: "removed", Predicate: "about"},
+		{ID: "changed", Predicate: "about"},
+	}}
+	candidate := &SuggestionsResponse{Suggestions: []Suggestion{
+		{ID: "changed", Predicate: "mentions"},
+		{ID: "kept", Predicate: "about", PrefLabel: "labels are not compared"},
+		{ID: "added-b", Predicate: "about"},
+		{ID: "added-a", Predicate: "mentions"},
+	}}
+
+	d := DiffSuggestions(primary, candidate)
+	assert.Equal(t, Diff{
+		Added:            []string{"added-a", "added-b"},
+		Removed:          []string{"removed"},
+		PredicateChanged: []string{"changed"},
+	}, d)
+	assert.False(t, d.Empty())
+	assert.True(t, DiffSuggestions(primary, primary).Empty())
+}
+
+func TestShadowUmbrellaAPI(t *testing.T) {
+	content := []byte(`{"uuid": "36320eb6-5617-4d12-9750-1907690e74db"}`)
+	expected := &SuggestionsResponse{Suggestions: []Suggestion{{ID: "person", Predicate: "about"}}}
+
+	api := &MockSuggestionsUmbrellaAPI{}
+	api.On("FetchSuggestions", mock.Anything, content).Return(expected, nil)
+	compared := make(chan struct{})
+	candidate := &MockSuggestionsUmbrellaAPI{}
+	candidate.On("Endpoint").Return("http://candidate:8080/content/suggest")
+	candidate.On("FetchSuggestions", mock.Anything, content).
+		Run(func(args mock.Arguments) {
+			assert.NoError(t, args.Get(0).(context.Context).Err(), "the candidate call outlives the request")
+		}).
+		Return(&SuggestionsResponse{Suggestions: []Suggestion{{ID: "person", Predicate: "mentions"}, {ID: "topic", Predicate: "about"}}}, nil).
+		Once()
+
+	registry := metrics.NewRegistry()
+	shadowAPI := NewShadowUmbrellaAPI(api, candidate, ShadowSettings{Timeout: time.Second, Concurrency: 1}, registry, logger.NewUPPLogger("Test", "PANIC"))
+	shadow := shadowAPI.(*shadowUmbrellaAPI)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := shadowAPI.FetchSuggestions(ctx, content)
+	cancel()
+	assert.NoError(t, err)
+	assert.Same(t, expected, resp, "the candidate suggestions are never served")
+
+	go func() {
+		// the slot is released once the comparison is done
+		shadow.slots <- struct{}{}
+		close(compared)
+	}()
+	select {
+	case <-compared:
+	case <-time.After(time.Second):
+		t.Fatal("the candidate was not compared")
+	}
+
+	candidate.AssertExpectations(t)
+	assert.Equal(t, int64(1), registry.Get("suggestions.shadow.calls").(metrics.Counter).Count())
+	assert.Equal(t, int64(1), registry.Get("suggestions.shadow.mismatches").(metrics.Counter).Count())
+	assert.Equal(t, int64(1), registry.Get("suggestions.shadow.concepts.added").(metrics.Counter).Count())
+	assert.Equal(t, int64(0), registry.Get("suggestions.shadow.concepts.removed").(metrics.Counter).Count())
+	assert.Equal(t, int64(1), registry.Get("suggestions.shadow.concepts.predicate_changed").(metrics.Counter).Count())
+}
+
+func TestShadowUmbrellaAPIDropsCallsWhenSaturated(t *testing.T) {
+	content := []byte(`{}`)
+
+	api := &MockSuggestionsUmbrellaAPI{}
+	api.On("FetchSuggestions", mock.Anything, content).Return(&SuggestionsResponse{}, nil)
+	candidate := &MockSuggestionsUmbrellaAPI{}
+
+	registry := metrics.NewRegistry()
+	shadowAPI := NewShadowUmbrellaAPI(api, candidate, ShadowSettings{Timeout: time.Second, Concurrency: 1}, registry, logger.NewUPPLogger("Test", "PANIC"))
+	shadowAPI.(*shadowUmbrellaAPI).slots <- struct{}{}
+
+	_, err := shadowAPI.FetchSuggestions(context.Background(), content)
+	assert.NoError(t, err)
+
+	candidate.AssertNotCalled(t, "FetchSuggestions", mock.Anything, mock.Anything)
+	assert.Equal(t, int64(1), registry.Get("suggestions.shadow.dropped").(metrics.Counter).Count())
+}
+
+func TestShadowUmbrellaAPIIsNotCalledOnErrors(t *testing.T) {
+	content := []byte(`{}`)
+
+	api := &MockSuggestionsUmbrellaAPI{}
+	api.On("FetchSuggestions", mock.Anything, content).Return((*SuggestionsResponse)(nil), errors.New("umbrella is down"))
+	candidate := &MockSuggestionsUmbrellaAPI{}
+
+	registry := metrics.NewRegistry()
+	shadowAPI := NewShadowUmbrellaAPI(api, candidate, ShadowSettings{Timeout: time.Second, Concurrency: 1}, registry, logger.NewUPPLogger("Test", "PANIC"))
+
+	_, err := shadowAPI.FetchSuggestions(context.Background(), content)
+	assert.EqualError(t, err, "umbrella is down")
+	assert.Equal(t, int64(0), registry.Get("suggestions.shadow.calls").(metrics.Counter).Count())
+}
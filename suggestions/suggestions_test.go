@@ -76,6 +76,7 @@ func TestUmbrellaAPI_FetchSuggestions(t *testing.T) {
 	suggestions, err := umbrellaAPI.FetchSuggestions(context.Background(), mockDraftContent)
 	assert.NoError(t, err)
 	assert.True(t, suggestions != nil)
+	assert.Len(t, suggestions.Suggestions, 2)
 }
 func TestUmbrellaAPI_FetchDraftContentFailure(t *testing.T) {
 	testServer := mocks.NewUmbrellaTestServer(false)
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExporterNone disables tracing, spans are still created but never recorded.
+	ExporterNone = "none"
+	// ExporterOTLP exports spans to an OpenTelemetry collector over OTLP/HTTP.
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans to the standard output, for local use.
+	ExporterStdout = "stdout"
+
+	instrumentationName = "github.com/Financial-Times/draft-content-suggestions"
+
+	// TransactionIDKey is the span attribute holding the FT transaction ID of the request.
+	TransactionIDKey = attribute.Key("ft.transaction_id")
+	// DependencyKey is the span attribute holding the downstream dependency of an outbound call.
+	DependencyKey = attribute.Key("ft.dependency")
+)
+
+// Config selects where spans are exported to.
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// OTLPEndpoint is the host:port of the OTLP/HTTP collector, the OTEL_EXPORTER_OTLP_* variables apply when empty.
+	OTLPEndpoint string
+	// SampleRatio is the ratio of new traces recorded. Traces started upstream follow their parent's decision.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+// The returned function flushes the spans not yet exported and must be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint), otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected one of: %s, %s, %s", cfg.Exporter, ExporterNone, ExporterOTLP, ExporterStdout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the %s tracing exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span named after the operation, as a child of the span in the context if any.
+func Start(ctx context.Context, operation string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, operation, trace.WithAttributes(attributes...))
+}
+
+// End records the error, if any, on the span before ending it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetTransactionID records the FT transaction ID on the span of the context, so that traces can be found from logs.
+func SetTransactionID(ctx context.Context, tid string) {
+	trace.SpanFromContext(ctx).SetAttributes(TransactionIDKey.String(tid))
+}
+
+// Middleware starts a server span for every request matched by a gorilla/mux route, continuing the trace of the
+// caller found in the traceparent header.
+func Middleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				return r.Method + " " + template
+			}
+		}
+		return r.Method
+	}))
+}
+
+// NewClient returns a copy of the base client creating a span for every call to the dependency,
+// and sending the trace context along in the traceparent header.
+func NewClient(base *http.Client, dependency string) *http.Client {
+	client := *base
+	client.Transport = Transport(base.Transport, dependency)
+	return &client
+}
+
+// Transport wraps the next http.RoundTripper so that its calls to the dependency are traced.
+func Transport(next http.RoundTripper, dependency string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return otelhttp.NewTransport(next,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + dependency
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(DependencyKey.String(dependency))),
+	)
+}
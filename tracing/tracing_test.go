@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return exporter
+}
+
+func TestClientPropagatesTraceContext(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := Start(context.Background(), "suggestions.FetchSuggestions")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	assert.NoError(t, err)
+	resp, err := NewClient(http.DefaultClient, "umbrella").Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	End(span, nil)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		client, parent := spans[0], spans[1]
+		assert.Equal(t, "POST umbrella", client.Name)
+		assert.Contains(t, client.Attributes, DependencyKey.String("umbrella"))
+		assert.Equal(t, parent.SpanContext.SpanID(), client.Parent.SpanID())
+		assert.Contains(t, traceparent, client.SpanContext.TraceID().String())
+	}
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/drafts/content/{uuid}/suggestions", func(w http.ResponseWriter, r *http.Request) {
+		SetTransactionID(r.Context(), "tid_test")
+	}).Methods("GET")
+	r.Use(Middleware)
+
+	req := httptest.NewRequest(http.MethodGet, "/drafts/content/6f14ea94-690f-3ed4-98c7-b926683c735a/suggestions", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "GET /drafts/content/{uuid}/suggestions", spans[0].Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+		assert.Contains(t, spans[0].Attributes, TransactionIDKey.String("tid_test"))
+	}
+}
+
+func TestEndRecordsError(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	_, span := Start(context.Background(), "draft.FetchDraftContent")
+	End(span, errors.New("error in draft content retrival status=500"))
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "error in draft content retrival status=500", spans[0].Status.Description)
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "jaeger"})
+
+	assert.EqualError(t, err, `unknown tracing exporter "jaeger", expected one of: none, otlp, stdout`)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	cli "github.com/jawher/mow.cli"
+
+	"github.com/Financial-Times/draft-content-suggestions/config"
+	"github.com/Financial-Times/draft-content-suggestions/endpointessentials"
+	"github.com/Financial-Times/draft-content-suggestions/platform"
+)
+
+// validateConfigCommand checks a validator configuration file without starting the service,
+// so that a broken file can be caught before it is deployed.
+func validateConfigCommand(cmd *cli.Cmd) {
+	validatorYml := cmd.String(cli.StringOpt{
+		Name:   "validator-yml",
+		Value:  "./config.yml",
+		Desc:   "Location of the Validator configuration YML file.",
+		EnvVar: "VALIDATOR_YML",
+	})
+	checkEndpoints := cmd.Bool(cli.BoolOpt{
+		Name:  "check-endpoints",
+		Value: false,
+		Desc:  "Also check that the __gtg endpoint of every configured validator is good-to-go",
+	})
+
+	cmd.Action = func() {
+		httpClient := &http.Client{Timeout: 10 * time.Second}
+		if !validateConfig(*validatorYml, *checkEndpoints, httpClient, os.Stdout) {
+			cli.Exit(1)
+		}
+	}
+}
+
+// validateConfig reports every problem of the configuration file to out, and whether it is valid.
+func validateConfig(path string, checkEndpoints bool, httpClient *http.Client, out io.Writer) bool {
+	cfg, err := config.ReadConfig(path)
+	if err != nil {
+		fmt.Fprintf(out, "%s: %v\n", path, err)
+		return false
+	}
+
+	valid := true
+	if err = cfg.Validate(); err != nil {
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			fmt.Fprintf(out, "%s: %v\n", path, err)
+			return false
+		}
+		for _, p := range validationErr.Problems {
+			fmt.Fprintln(out, p.Error())
+		}
+		valid = false
+	}
+
+	if checkEndpoints {
+		for _, endpoint := range validatorEndpoints(cfg) {
+			if err := platform.NewService(endpoint, httpClient).GTG(); err != nil {
+				fmt.Fprintf(out, "%s: %s is not good-to-go: %v\n", path, endpoint, err)
+				valid = false
+			}
+		}
+	}
+
+	if valid {
+		fmt.Fprintf(out, "%s: OK\n", path)
+	}
+	return valid
+}
+
+// validatorEndpoints returns the well-formed endpoints of the configured validators, the malformed ones
+// being already reported by the validation.
+func validatorEndpoints(cfg *config.Config) []string {
+	unique := map[string]bool{}
+	for _, validatorConfig := range cfg.ContentTypes {
+		// the stages of chained validators are checked on their own
+		for _, stage := range append([]config.ValidatorConfig{validatorConfig}, validatorConfig.Chain...) {
+			if endpointessentials.ValidateEndpoint(stage.Endpoint) == nil {
+				unique[stage.Endpoint] = true
+			}
+		}
+	}
+
+	endpoints := make([]string, 0, len(unique))
+	for endpoint := range unique {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeValidatorYml(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateConfigValid(t *testing.T) {
+	var out bytes.Buffer
+
+	assert.True(t, validateConfig("config.yml", false, http.DefaultClient, &out))
+	assert.Equal(t, "config.yml: OK\n", out.String())
+}
+
+func TestValidateConfigProblems(t *testing.T) {
+	path := writeValidatorYml(t, `content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "genric"
+    end-point: "upp-article-validator:8080"
+`)
+	var out bytes.Buffer
+
+	assert.False(t, validateConfig(path, false, http.DefaultClient, &out))
+	assert.Equal(t,
+		path+`:3:16: unknown validator "genric" for content-type application/vnd.ft-upp-article+json, expected one of: generic, jsonschema, passthrough, chained`+"\n"+
+			path+":4:16: invalid end-point for content-type application/vnd.ft-upp-article+json: missing scheme in endpoint: upp-article-validator:8080\n",
+		out.String())
+}
+
+func TestValidateConfigNotFound(t *testing.T) {
+	var out bytes.Buffer
+
+	assert.False(t, validateConfig("no-such-file.yml", false, http.DefaultClient, &out))
+	assert.Contains(t, out.String(), "no-such-file.yml: open no-such-file.yml")
+}
+
+func TestValidateConfigCheckEndpoints(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/__gtg", r.URL.Path)
+	}))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	path := writeValidatorYml(t, `content-types:
+  "application/vnd.ft-upp-article+json":
+    validator: "generic"
+    end-point: "`+healthy.URL+`"
+  "application/vnd.ft-upp-content-placeholder+json":
+    validator: "generic"
+    end-point: "`+unhealthy.URL+`"
+`)
+
+	var out bytes.Buffer
+	assert.True(t, validateConfig(path, false, http.DefaultClient, &out), "endpoints are only probed on demand")
+
+	out.Reset()
+	assert.False(t, validateConfig(path, true, http.DefaultClient, &out))
+	assert.Equal(t, path+": "+unhealthy.URL+" is not good-to-go: gtg returned a non-200 HTTP status: 503 - \n", out.String())
+}